@@ -0,0 +1,34 @@
+/*
+Package anomaly provides anomaly scoring for gohtm.
+
+A raw anomaly score measures how unexpected the current input
+was, given the prediction made in the previous time step. Raw
+scores are noisy, so a Likelihood can be used to estimate how
+unusual recent raw scores are compared to their own history.
+*/
+package anomaly
+
+// Raw returns the fraction of active columns that were not predicted
+// in the previous time step. A score of 0.0 means every active column
+// was predicted, 1.0 means none were. If no columns are active there
+// is nothing to be surprised about, and the score is 0.0.
+func Raw(predicted, active []bool) float64 {
+	if len(predicted) != len(active) {
+		panic("anomaly: mismatched input dimensions")
+	}
+
+	var nActive, nGood int
+	for i := range active {
+		if active[i] {
+			nActive++
+			if predicted[i] {
+				nGood++
+			}
+		}
+	}
+
+	if nActive == 0 {
+		return 0.0
+	}
+	return float64(nActive-nGood) / float64(nActive)
+}
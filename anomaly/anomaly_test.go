@@ -0,0 +1,78 @@
+package anomaly
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRaw(t *testing.T) {
+	for _, c := range []struct {
+		predicted, active []bool
+		want              float64
+	}{
+		{[]bool{true, true, false, false}, []bool{true, true, false, false}, 0.0},
+		{[]bool{true, false, false, false}, []bool{true, true, false, false}, 0.5},
+		{[]bool{false, false, true, true}, []bool{true, true, false, false}, 1.0},
+		{[]bool{true, false, false, false}, []bool{true, true, true, false}, 2.0 / 3.0},
+		{[]bool{true, true, false, false}, []bool{false, false, false, false}, 0.0},
+		{[]bool{}, []bool{}, 0.0},
+	} {
+		if got := Raw(c.predicted, c.active); got != c.want {
+			t.Fatalf("Raw(%v, %v) = %v, want %v", c.predicted, c.active, got, c.want)
+		}
+	}
+}
+
+func TestLikelihoodWarmup(t *testing.T) {
+	p := NewLikelihoodParams()
+	l := NewLikelihood(p)
+	for i := 0; i < p.LearnPeriod; i++ {
+		if got := l.Compute(float64(i%2)); got != 0.5 {
+			t.Fatalf("step %d: likelihood %v during warm up, want 0.5", i, got)
+		}
+	}
+	if got := l.Compute(0.5); got == 0.5 {
+		t.Fatal("likelihood still 0.5 after warm up")
+	}
+}
+
+func TestLikelihoodStepChange(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	l := NewLikelihood(NewLikelihoodParams())
+
+	var before float64
+	for i := 0; i < 2048; i++ {
+		before = l.Compute(rng.Float64() * 0.2)
+	}
+
+	// raw scores jump from [0 : 0.2) to [0.8 : 1.0)
+	var after float64
+	for i := 0; i < 8; i++ {
+		after = l.Compute(0.8 + rng.Float64()*0.2)
+	}
+	switch {
+	case before > 0.99:
+		t.Fatalf("likelihood %.4f on a steady stream", before)
+	case after < 0.9999:
+		t.Fatalf("likelihood %.4f after a step change, want > 0.9999", after)
+	case LogLikelihood(after) <= LogLikelihood(before):
+		t.Fatal("log likelihood did not rise after a step change")
+	}
+}
+
+func TestNewLikelihoodValidates(t *testing.T) {
+	for _, p := range []LikelihoodParams{
+		{Window: 0, ShortWindow: 1},
+		{Window: 8, ShortWindow: 0},
+		{Window: 8, ShortWindow: 16},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%+v: no panic", p)
+				}
+			}()
+			NewLikelihood(p)
+		}()
+	}
+}
@@ -0,0 +1,126 @@
+package anomaly
+
+import "math"
+
+// minStdDev keeps the distribution from collapsing on perfectly
+// regular streams, where any deviation would otherwise be flagged.
+const minStdDev = 0.03
+
+// LikelihoodParams contains parameters for initialization of a Likelihood.
+type LikelihoodParams struct {
+	Window      int `json:"window"`
+	ShortWindow int `json:"shortwindow"`
+	LearnPeriod int `json:"learnperiod"`
+}
+
+// NewLikelihoodParams returns a default set of LikelihoodParams.
+func NewLikelihoodParams() LikelihoodParams {
+	return LikelihoodParams{
+		Window:      1024, // raw scores the distribution is fit over
+		ShortWindow: 8,    // raw scores averaged for each test
+		LearnPeriod: 256,  // raw scores seen before reporting
+	}
+}
+
+// Likelihood models raw anomaly scores over a moving window as a
+// normal distribution, and reports how likely the recent average
+// raw score is to be anomalous under that model.
+type Likelihood struct {
+	P LikelihoodParams `json:"params"`
+
+	scores     []float64 // ring buffer of the last Window raw scores
+	idx        int
+	sum, sumSq float64
+	short      []float64 // ring buffer of the last ShortWindow raw scores
+	shortIdx   int
+	shortSum   float64
+	iteration  int
+}
+
+// NewLikelihood returns a new Likelihood initialized with the
+// provided LikelihoodParams. Both windows must hold at least one
+// score, and ShortWindow must not be longer than Window.
+func NewLikelihood(p LikelihoodParams) *Likelihood {
+	switch {
+	case p.Window <= 0:
+		panic("anomaly: window must be > 0")
+	case p.ShortWindow <= 0:
+		panic("anomaly: short window must be > 0")
+	case p.ShortWindow > p.Window:
+		panic("anomaly: short window is longer than window")
+	}
+
+	return &Likelihood{
+		P:      p,
+		scores: make([]float64, 0, p.Window),
+		short:  make([]float64, 0, p.ShortWindow),
+	}
+}
+
+// Compute records a raw anomaly score and returns the current anomaly
+// likelihood, between 0.0 and 1.0. Until LearnPeriod scores have been
+// recorded there is not enough history to judge, and 0.5 is returned.
+func (l *Likelihood) Compute(raw float64) float64 {
+	l.push(raw)
+	l.iteration++
+
+	if l.iteration <= l.P.LearnPeriod {
+		return 0.5
+	}
+
+	n := float64(len(l.scores))
+	mean := l.sum / n
+	variance := l.sumSq/n - mean*mean
+	sd := math.Sqrt(math.Max(variance, 0.0))
+	if sd < minStdDev {
+		sd = minStdDev
+	}
+
+	z := (l.shortSum/float64(len(l.short)) - mean) / sd
+	return 1.0 - tailProbability(z)
+}
+
+// push adds a raw score to both moving windows, evicting the oldest
+// scores once a window is full.
+func (l *Likelihood) push(raw float64) {
+	switch {
+	case len(l.scores) < l.P.Window:
+		l.scores = append(l.scores, raw)
+	default:
+		old := l.scores[l.idx]
+		l.sum -= old
+		l.sumSq -= old * old
+		l.scores[l.idx] = raw
+		l.idx = (l.idx + 1) % l.P.Window
+	}
+	l.sum += raw
+	l.sumSq += raw * raw
+
+	switch {
+	case len(l.short) < l.P.ShortWindow:
+		l.short = append(l.short, raw)
+	default:
+		l.shortSum -= l.short[l.shortIdx]
+		l.short[l.shortIdx] = raw
+		l.shortIdx = (l.shortIdx + 1) % l.P.ShortWindow
+	}
+	l.shortSum += raw
+}
+
+// Reset discards all recorded raw scores.
+func (l *Likelihood) Reset() {
+	*l = *NewLikelihood(l.P)
+}
+
+// LogLikelihood rescales a likelihood to a logarithmic scale, which
+// spreads out the values close to 1.0 that matter most. A likelihood
+// of 0.5 maps to ~0.03, 0.9999 maps to ~0.4, and 0.9999999999 to 1.0.
+func LogLikelihood(likelihood float64) float64 {
+	return math.Log(1.0000000001-likelihood) / math.Log(1.0-0.9999999999)
+}
+
+// tailProbability returns the probability that a standard normal
+// variable is greater than z.
+func tailProbability(z float64) float64 {
+	return 0.5 * math.Erfc(z/math.Sqrt2)
+}
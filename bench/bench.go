@@ -0,0 +1,117 @@
+/*
+Package bench provides benchmark sequences for measuring the
+prediction accuracy and performance of gohtm models.
+
+Each generator returns a Stream, which can be run through a
+spatial pooler and temporal memory with Run. Run reports window
+averages of prediction accuracy, anomaly, model growth and
+iteration speed, so learning curves and slowdown over time can
+be tracked.
+*/
+package bench
+
+import (
+	"math"
+	"time"
+
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/tm"
+)
+
+// Params contains parameters for a benchmark run.
+type Params struct {
+	N      uint32      `json:"n"`      // encoder width
+	W      int         `json:"w"`      // encoder active bits
+	Window int         `json:"window"` // iterations per report
+	SP     sp.V2Params `json:"sp"`
+	TM     tm.V1Params `json:"tm"`
+}
+
+// NewParams returns a default set of Params.
+func NewParams() Params {
+	p := Params{
+		N:      1024,
+		W:      40,
+		Window: 100,
+		SP:     sp.NewV2Params(),
+		TM:     tm.NewV1Params(),
+	}
+	p.SP.NumInputs = int(p.N)
+	p.TM.NumColumns = p.SP.NumColumns
+	return p
+}
+
+// Report contains measurements averaged over one window of a run.
+type Report struct {
+	Iteration int     `json:"iteration"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	Anomaly   float64 `json:"anomaly"`
+	Segments  int     `json:"segments"`
+	Synapses  int     `json:"synapses"`
+	MsPerIter float64 `json:"msperiter"`
+}
+
+// Run feeds a Stream through a freshly initialized encoder, spatial
+// pooler and temporal memory with learning enabled, and returns one
+// Report for every p.Window iterations. A Window <= 0 returns a single
+// Report covering the whole Stream.
+func Run(s Stream, p Params) []Report {
+	if p.Window <= 0 {
+		p.Window = len(s.Values)
+	}
+	if p.Window == 0 {
+		return nil
+	}
+
+	e := enc.NewRDScalar(p.N, p.W, 0, s.Resolution)
+	sr := sp.NewV2(p.SP)
+	tr := tm.NewV1(p.TM)
+
+	reports := make([]Report, 0, len(s.Values)/p.Window)
+	var cur Report
+	var elapsed time.Duration
+	var n int
+
+	for i := range s.Values {
+		start := time.Now()
+		if s.Resets[i] {
+			tr.Reset()
+		}
+		input, _ := e.Encode(s.Values[i])
+		tr.Compute(sr.Compute(input, true), true)
+		elapsed += time.Since(start)
+
+		m := tr.GetMetrics()
+		cur.Precision += m.Precision
+		cur.Recall += m.Recall
+		cur.Anomaly += m.Anomaly
+		n++
+
+		if n == p.Window || i == len(s.Values)-1 {
+			cur.Iteration = i + 1
+			cur.Precision /= float64(n)
+			cur.Recall /= float64(n)
+			cur.Anomaly /= float64(n)
+			cur.Segments, cur.Synapses = tr.GetStats()
+			cur.MsPerIter = elapsed.Seconds() * 1000 / float64(n)
+			reports = append(reports, cur)
+
+			cur, elapsed, n = Report{}, 0, 0
+		}
+	}
+
+	return reports
+}
+
+// Slowdown returns the average growth in ms/iteration between
+// consecutive reports, as a ratio. A return value of 0.01 means each
+// window ran 1% slower than the one before it.
+func Slowdown(r []Report) float64 {
+	if len(r) < 2 || r[0].MsPerIter <= 0 {
+		return 0.0
+	}
+	ratio := r[len(r)-1].MsPerIter / r[0].MsPerIter
+	return math.Pow(ratio, 1.0/float64(len(r)-1)) - 1.0
+}
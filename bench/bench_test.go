@@ -0,0 +1,86 @@
+package bench
+
+import "testing"
+
+// benchParams returns Params for a model small enough to benchmark.
+func benchParams() Params {
+	p := NewParams()
+	p.SP.NumColumns = 1024
+	p.TM.NumColumns = 1024
+	p.TM.CellsPerCol = 8
+	return p
+}
+
+// benchmark runs a Stream of b.N datapoints, so each op is one step.
+func benchmark(b *testing.B, s Stream) {
+	p := benchParams()
+	p.Window = 0
+	b.ResetTimer()
+	Run(s, p)
+}
+
+func BenchmarkHighOrder(b *testing.B) {
+	benchmark(b, HighOrder(b.N, 2, 6, 1))
+}
+
+func BenchmarkNoisySine(b *testing.B) {
+	benchmark(b, NoisySine(b.N, 0.05, 1))
+}
+
+func BenchmarkRepeated(b *testing.B) {
+	benchmark(b, Repeated(b.N, 8, 1))
+}
+
+func BenchmarkBranching(b *testing.B) {
+	benchmark(b, Branching(b.N, 3, 2, 3, 1))
+}
+
+func TestRunWindow(t *testing.T) {
+	s := Repeated(50, 4, 1)
+	p := benchParams()
+
+	p.Window = 0
+	if r := Run(s, p); len(r) != 1 || r[0].Iteration != 50 {
+		t.Fatalf("window 0: got %d reports, want 1 covering 50", len(r))
+	}
+
+	p.Window = 20
+	if r := Run(s, p); len(r) != 3 {
+		t.Fatalf("window 20: got %d reports, want 3", len(r))
+	}
+}
+
+func TestNoisySineSeeded(t *testing.T) {
+	a, b := NoisySine(100, 0.1, 7), NoisySine(100, 0.1, 7)
+	for i := range a.Values {
+		if a.Values[i] != b.Values[i] {
+			t.Fatalf("value %d differs between runs with the same seed", i)
+		}
+		if a.Values[i] < 0 || a.Values[i] > 1 {
+			t.Fatalf("value %d = %f outside [0 : 1]", i, a.Values[i])
+		}
+	}
+}
+
+func TestGeneratorsValidate(t *testing.T) {
+	for name, gen := range map[string]func(){
+		"high order, no sequences": func() { HighOrder(10, 0, 5, 1) },
+		"high order, length 1":     func() { HighOrder(10, 2, 1, 1) },
+		"repeated, no symbols":     func() { Repeated(10, 0, 1) },
+		"branching, no branches":   func() { Branching(10, 3, 0, 3, 1) },
+		"branching, length 0":      func() { Branching(10, 0, 2, 0, 1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: no panic", name)
+				}
+			}()
+			gen()
+		}()
+	}
+
+	if s := HighOrder(10, 1, 2, 1); len(s.Values) != 10 {
+		t.Fatalf("high order, length 2: %d values, want 10", len(s.Values))
+	}
+}
@@ -0,0 +1,137 @@
+package bench
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Stream is a sequence of datapoints to feed through a model. Resets
+// marks the datapoints that begin a new sequence, and Resolution is
+// the encoder resolution that keeps distinct datapoints apart.
+type Stream struct {
+	Name       string
+	Values     []float64
+	Resets     []bool
+	Resolution float64
+}
+
+func newStream(name string, n int, resolution float64) Stream {
+	return Stream{
+		Name:       name,
+		Values:     make([]float64, 0, n),
+		Resets:     make([]bool, 0, n),
+		Resolution: resolution,
+	}
+}
+
+func (s *Stream) push(v float64, reset bool) {
+	s.Values = append(s.Values, v)
+	s.Resets = append(s.Resets, reset)
+}
+
+// HighOrder generates n datapoints from a set of sequences that share
+// a common middle section, but differ in their first and last symbols.
+// Predicting the last symbol requires context from the first, so only
+// a model with high order memory can score well.
+//
+// With 2 sequences of length 5, the stream is built out of:
+//
+//	A x y z B
+//	C x y z D
+//
+// HighOrder panics unless sequences >= 1 and length >= 2.
+func HighOrder(n, sequences, length int, seed int64) Stream {
+	switch {
+	case sequences < 1:
+		panic("bench: high order streams need at least 1 sequence")
+	case length < 2:
+		panic("bench: high order sequences need a length of at least 2")
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	s := newStream("high-order", n, 1.0)
+
+	// symbols 0 : length-2 are shared, the rest are unique
+	shared := length - 2
+	seqs := make([][]float64, sequences)
+	for i := range seqs {
+		seqs[i] = make([]float64, length)
+		seqs[i][0] = float64(shared + 2*i)
+		for j := 1; j <= shared; j++ {
+			seqs[i][j] = float64(j - 1)
+		}
+		seqs[i][length-1] = float64(shared + 2*i + 1)
+	}
+
+	for len(s.Values) < n {
+		seq := seqs[rng.Intn(len(seqs))]
+		for i := 0; i < len(seq) && len(s.Values) < n; i++ {
+			s.push(seq[i], i == 0)
+		}
+	}
+	return s
+}
+
+// NoisySine generates n datapoints of a sine wave in [0.0 : 1.0], with
+// uniform noise of up to +/- noise added to each. It matches the output
+// of vec.SineGen with an amplitude of 1.0, but draws noise from a
+// seeded source.
+func NoisySine(n int, noise float64, seed int64) Stream {
+	rng := rand.New(rand.NewSource(seed))
+	s := newStream("noisy-sine", n, 0.02)
+
+	dx := (math.Pi * 2) / 64.0
+	for i := 0; i < n; i++ {
+		y := math.Sin(float64(i)*dx)*0.5 + 0.5
+		y += (rng.Float64()*2 - 1) * noise
+		s.push(math.Min(math.Max(y, 0.0), 1.0), false)
+	}
+	return s
+}
+
+// Repeated generates n datapoints by cycling through a random sequence
+// of the provided number of symbols, with no resets. Repeated panics
+// unless symbols >= 1.
+func Repeated(n, symbols int, seed int64) Stream {
+	if symbols < 1 {
+		panic("bench: repeated streams need at least 1 symbol")
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	s := newStream("repeated", n, 1.0)
+
+	seq := rng.Perm(symbols)
+	for i := 0; i < n; i++ {
+		s.push(float64(seq[i%len(seq)]), false)
+	}
+	return s
+}
+
+// Branching generates n datapoints from sequences that share a common
+// prefix, then continue with one of several randomly chosen branches.
+// The best possible model predicts the union of all branches at the
+// branch point, so accuracy is bounded by 1 / branches there.
+//
+// Branching panics unless branches >= 1 and prefix + length >= 1.
+func Branching(n, prefix, branches, length int, seed int64) Stream {
+	switch {
+	case branches < 1:
+		panic("bench: branching streams need at least 1 branch")
+	case prefix < 0 || length < 0 || prefix+length < 1:
+		panic("bench: branching sequences need a length of at least 1")
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	s := newStream("branching", n, 1.0)
+
+	for len(s.Values) < n {
+		b := rng.Intn(branches)
+		for i := 0; i < prefix && len(s.Values) < n; i++ {
+			s.push(float64(i), i == 0)
+		}
+		for i := 0; i < length && len(s.Values) < n; i++ {
+			s.push(float64(prefix+b*length+i), false)
+		}
+	}
+	return s
+}
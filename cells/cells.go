@@ -32,10 +32,12 @@ type Cells interface {
 
 	ComputeActivity(active []bool, connected float32,
 		activeThreshold, matchThreshold int)
-	Cleanup()
+	Cleanup(maxAge int) (segs, syns int)
+	Decay(amount float32)
 	Clear()
 	StartNewIteration()
 
 	ComputePredictedCols() []bool
+	ActiveSegsPerCell() []int
 	ComputeStats() (int, int)
 }
@@ -66,6 +66,7 @@ func (v *V1) CreateSegment(cell int) int {
 			V1Segment{
 				active:   false,
 				matching: false,
+				lastIter: v.iteration,
 				Synapses: make([]V1Synapse, 0)})
 	} else {
 		// TODO :: how do we handle this?
@@ -121,6 +122,8 @@ func (v *V1) CreateSynapse(cell, seg, target int, perm float32) {
 func (v *V1) AdaptSegment(cell, seg int, prevActive []bool,
 	inc, dec float32) {
 
+	v.Cells[cell].Segments[seg].lastIter = v.iteration
+
 	var perm float32
 	for i := range v.Cells[cell].Segments[seg].Synapses {
 		perm = v.Cells[cell].Segments[seg].Synapses[i].Perm
@@ -330,6 +333,7 @@ func (v *V1) ComputeActivity(active []bool, connected float32,
 			switch {
 			case live >= activeThreshold:
 				v.Cells[i].Segments[j].active = true
+				v.Cells[i].Segments[j].lastIter = v.iteration
 				v.Cells[i].active++
 				fallthrough
 			case dead >= matchThreshold:
@@ -343,27 +347,58 @@ func (v *V1) ComputeActivity(active []bool, connected float32,
 }
 
 // Cleanup traverses all cells, segments, and synapses, performing
-// maintenance. Segments with 0 synapses and synapses with a
-// permanence value of < 0.001 are destroyed.
-func (v *V1) Cleanup() {
+// maintenance. Synapses with a permanence value of < 0.001, segments
+// with 0 synapses, and if maxAge > 0, segments that have not been
+// active, adapted or created in the last maxAge iterations are
+// destroyed. The number of destroyed segments and synapses is
+// returned; synapses on destroyed segments are counted.
+func (v *V1) Cleanup(maxAge int) (int, int) {
+	var nSegs, nSyns int
 	for i := range v.Cells {
-	restartSegs:
-		for j := range v.Cells[i].Segments {
-		restartSyns:
-			for k := range v.Cells[i].Segments[j].Synapses {
-				if v.Cells[i].Segments[j].Synapses[k].Perm < 0.001 {
-					v.Cells[i].Segments[j].Synapses = append(
-						v.Cells[i].Segments[j].Synapses[:k],
-						v.Cells[i].Segments[j].Synapses[k+1:]...)
-					goto restartSyns
+		c := &v.Cells[i]
+		segs := c.Segments[:0]
+		for _, seg := range c.Segments {
+			syns := seg.Synapses[:0]
+			for _, syn := range seg.Synapses {
+				if syn.Perm >= 0.001 {
+					syns = append(syns, syn)
 				}
 			}
+			nSyns += len(seg.Synapses) - len(syns)
+			seg.Synapses = syns
+
+			stale := maxAge > 0 && v.iteration-seg.lastIter > maxAge
+			if len(seg.Synapses) > 0 && !stale {
+				segs = append(segs, seg)
+				continue
+			}
 
-			if len(v.Cells[i].Segments[j].Synapses) == 0 {
-				v.Cells[i].Segments = append(
-					v.Cells[i].Segments[:j],
-					v.Cells[i].Segments[j+1:]...)
-				goto restartSegs
+			// keep activity counts of the cell in sync
+			nSegs++
+			nSyns += len(seg.Synapses)
+			if seg.active {
+				c.active--
+			}
+			if seg.matching {
+				c.matching--
+			}
+		}
+		c.Segments = segs
+	}
+	return nSegs, nSyns
+}
+
+// Decay weakens every synapse by amount, so synapses that are not
+// reinforced are eventually forgotten by Cleanup.
+func (v *V1) Decay(amount float32) {
+	for i := range v.Cells {
+		for j := range v.Cells[i].Segments {
+			syns := v.Cells[i].Segments[j].Synapses
+			for k := range syns {
+				syns[k].Perm -= amount
+				if syns[k].Perm < 0.0 {
+					syns[k].Perm = 0.0
+				}
 			}
 		}
 	}
@@ -403,6 +438,16 @@ func (v *V1) ComputePredictedCols() []bool {
 	return prediction
 }
 
+// ActiveSegsPerCell returns the number of active segments on each
+// cell. Cells with any active segments are depolarized.
+func (v *V1) ActiveSegsPerCell() []int {
+	counts := make([]int, len(v.Cells))
+	for i := range v.Cells {
+		counts[i] = v.Cells[i].active
+	}
+	return counts
+}
+
 // ComputeStats returns the total number of segments and synapses.
 func (v *V1) ComputeStats() (int, int) {
 	var nSegs, nSyns int
@@ -0,0 +1,117 @@
+package cells
+
+import "testing"
+
+// segment creates a segment on cell with synapses to targets at the
+// provided permanences, and returns its index.
+func segment(v *V1, cell int, syns map[int]float32) int {
+	seg := v.CreateSegment(cell)
+	for target, perm := range syns {
+		v.CreateSynapse(cell, seg, target, perm)
+	}
+	return seg
+}
+
+// ages returns the iteration at which each segment on cell was last
+// used.
+func ages(v *V1, cell int) []int {
+	var out []int
+	for _, seg := range v.Cells[cell].Segments {
+		out = append(out, seg.lastIter)
+	}
+	return out
+}
+
+func TestCleanup(t *testing.T) {
+	for _, c := range []struct {
+		maxAge     int
+		segs, syns int
+		kept       [4]int // segments left on each cell
+	}{
+		// the weak synapse on cell 1, and the all weak segment on
+		// cell 3 with its 2 synapses
+		{0, 1, 3, [4]int{1, 1, 1, 0}},
+		// and the segment on cell 0, unused for 10 iterations
+		{5, 2, 5, [4]int{0, 1, 1, 0}},
+		// and the segment on cell 2, adapted 2 iterations ago
+		{1, 3, 7, [4]int{0, 1, 0, 0}},
+	} {
+		v := NewV1(V1Params{NumColumns: 4, CellsPerCol: 2, SegsPerCell: 4, SynsPerSeg: 8})
+
+		segment(v, 0, map[int]float32{4: 0.5, 5: 0.5})
+		c2 := segment(v, 2, map[int]float32{6: 0.5, 7: 0.3})
+		for i := 0; i < 8; i++ {
+			v.StartNewIteration()
+		}
+		v.AdaptSegment(2, c2, make([]bool, 8), 0.1, 0.1)
+		v.StartNewIteration()
+		v.StartNewIteration()
+		segment(v, 1, map[int]float32{4: 0.5, 5: 0.0005})
+		segment(v, 3, map[int]float32{4: 0.0, 5: 0.0009})
+
+		if got := ages(v, 2); got[0] != 8 {
+			t.Fatalf("adapted segment last used at %v, want 8", got)
+		}
+
+		segs, syns := v.Cleanup(c.maxAge)
+		if segs != c.segs || syns != c.syns {
+			t.Errorf("maxAge %d: pruned %d segments and %d synapses, want %d and %d",
+				c.maxAge, segs, syns, c.segs, c.syns)
+		}
+		for cell, n := range c.kept {
+			if len(v.Cells[cell].Segments) != n {
+				t.Errorf("maxAge %d: cell %d has %d segments, want %d",
+					c.maxAge, cell, len(v.Cells[cell].Segments), n)
+			}
+		}
+		if len(v.Cells[1].Segments) == 1 && len(v.Cells[1].Segments[0].Synapses) != 1 {
+			t.Errorf("maxAge %d: weak synapse was kept", c.maxAge)
+		}
+	}
+}
+
+func TestCleanupActivity(t *testing.T) {
+	v := NewV1(V1Params{NumColumns: 2, CellsPerCol: 2, SegsPerCell: 4, SynsPerSeg: 8})
+	segment(v, 0, map[int]float32{2: 0.5, 3: 0.5})
+	seg := segment(v, 0, map[int]float32{2: 0.5, 3: 0.5})
+
+	active := []bool{false, false, true, true}
+	v.ComputeActivity(active, 0.5, 2, 1)
+	if v.ActiveSegsForCol(0) != 2 {
+		t.Fatalf("%d active segments, want 2", v.ActiveSegsForCol(0))
+	}
+
+	// pruning an active segment keeps the cell's counts in sync
+	v.Decay(0.5)
+	v.Cells[0].Segments[seg].Synapses[0].Perm = 0.5
+	v.Cells[0].Segments[seg].Synapses[1].Perm = 0.5
+	if segs, syns := v.Cleanup(0); segs != 1 || syns != 2 {
+		t.Fatalf("pruned %d segments and %d synapses, want 1 and 2", segs, syns)
+	}
+	if v.ActiveSegsForCol(0) != 1 || v.MatchingSegsForCol(0) != 1 {
+		t.Fatalf("%d active and %d matching segments after cleanup, want 1 and 1",
+			v.ActiveSegsForCol(0), v.MatchingSegsForCol(0))
+	}
+}
+
+func TestDecay(t *testing.T) {
+	v := NewV1(V1Params{NumColumns: 2, CellsPerCol: 2, SegsPerCell: 4, SynsPerSeg: 8})
+	segment(v, 0, map[int]float32{1: 0.5, 2: 0.05, 3: 1.0})
+	segment(v, 3, map[int]float32{0: 0.25})
+
+	v.Decay(0.1)
+	want := map[int]float32{1: 0.4, 2: 0.0, 3: 0.9}
+	for _, syn := range v.Cells[0].Segments[0].Synapses {
+		if d := syn.Perm - want[syn.Cell]; d > 1e-6 || d < -1e-6 {
+			t.Errorf("synapse to %d decayed to %v, want %v", syn.Cell, syn.Perm, want[syn.Cell])
+		}
+	}
+	if p := v.Cells[3].Segments[0].Synapses[0].Perm; p < 0.149999 || p > 0.150001 {
+		t.Errorf("synapse decayed to %v, want 0.15", p)
+	}
+
+	// decayed to 0, the synapse is pruned by the next cleanup
+	if segs, syns := v.Cleanup(0); segs != 0 || syns != 1 {
+		t.Fatalf("pruned %d segments and %d synapses, want 0 and 1", segs, syns)
+	}
+}
@@ -21,6 +21,10 @@ type Classifier interface {
 		sdr []int, bidx int, actValue float64,
 		learn, infer bool) Result
 
+	// Reset clears the pattern history, so patterns from before the
+	// reset are not associated with values after it.
+	Reset()
+
 	// TODO
 	// compute::encoderBucket, activeCells
 	// infer::depolarizedCells -> Results
@@ -1,9 +1,9 @@
 package cla
 
 type V2Params struct {
-	Steps         []int
-	Alpha         float64
-	ActValueAlpha float64
+	Steps         []int   `json:"steps"`
+	Alpha         float64 `json:"alpha"`
+	ActValueAlpha float64 `json:"actvaluealpha"`
 }
 
 func NewV2Params() V2Params {
@@ -64,6 +64,11 @@ func (c *V2) Compute(
 	return Result{}
 }
 
+// Reset clears the pattern history.
+func (c *V2) Reset() {
+	c.patternHistory = c.patternHistory[:0]
+}
+
 func (c *V2) infer() {
 }
 
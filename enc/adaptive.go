@@ -0,0 +1,196 @@
+package enc
+
+import (
+	"math"
+	"sort"
+)
+
+// AdaptiveParams represents a parameter set for an Adaptive encoder.
+type AdaptiveParams struct {
+	Buckets  int     `json:"buckets"`
+	Active   int     `json:"active"`
+	Window   int     `json:"window"`   // values the range is tracked over
+	Quantile float64 `json:"quantile"` // fraction of outliers ignored at each end
+	Padding  float64 `json:"padding"`  // fraction of range added at each end
+	Shrink   float64 `json:"shrink"`   // narrow when range falls below this fraction
+}
+
+// NewAdaptiveParams returns a default param set.
+func NewAdaptiveParams() AdaptiveParams {
+	return AdaptiveParams{
+		Buckets:  100,
+		Active:   21,
+		Window:   1000,
+		Quantile: 0.0,
+		Padding:  0.1,
+		Shrink:   0.5,
+	}
+}
+
+// Shift describes a change in the value to bucket mapping of an
+// Adaptive encoder.
+type Shift struct {
+	Iteration int          `json:"iteration"`
+	Old       ScalarParams `json:"old"`
+	New       ScalarParams `json:"new"`
+}
+
+// Remap returns the bucket index under the new mapping that holds the
+// value of bucket b under the old mapping.
+func (s Shift) Remap(b int) int {
+	old := NewScalar(s.Old)
+	v := s.Old.Min
+	if s.Old.Buckets > 1 {
+		v += float64(b) * old.Range / float64(s.Old.Buckets-1)
+	}
+	return NewScalar(s.New).Bucket(v)
+}
+
+// Adaptive is a scalar encoder for streams of unknown range. It tracks
+// the range of the last Window values, and encodes with a Scalar
+// encoder fit to that range.
+//
+// The mapping only changes when a value falls outside the current
+// range, or when the tracked range shrinks below Shrink of the current
+// range, and is padded on each change so small drifts don't cause a
+// shift on every step. Each shift is passed to the functions registered
+// with Notify, so downstream classifiers can remap their buckets.
+type Adaptive struct {
+	P         AdaptiveParams `json:"params"`
+	Scalar    *Scalar        `json:"scalar"`
+	Iteration int            `json:"iteration"`
+	window    []float64
+	idx       int
+	notify    []func(Shift)
+}
+
+// NewAdaptive returns an Adaptive encoder initialized with the provided
+// AdaptiveParams.
+func NewAdaptive(p AdaptiveParams) *Adaptive {
+	switch {
+	case p.Window <= 0:
+		panic("Adaptive window must be > 0!")
+	case p.Buckets <= 0:
+		panic("Adaptive buckets must be > 0!")
+	case p.Quantile < 0 || p.Quantile >= 0.5:
+		panic("Adaptive quantile must be in [0 : 0.5)!")
+	}
+
+	return &Adaptive{
+		P:      p,
+		window: make([]float64, 0, p.Window),
+	}
+}
+
+// Notify registers fn to be called on every shift of the mapping.
+func (a *Adaptive) Notify(fn func(Shift)) {
+	a.notify = append(a.notify, fn)
+}
+
+// Copy returns a deep copy of a. Functions registered with Notify are
+// not copied.
+func (a *Adaptive) Copy() Encoder {
+	c := *a
+	c.window = make([]float64, len(a.window), a.P.Window)
+	copy(c.window, a.window)
+	c.notify = nil
+	return &c
+}
+
+// Resolution returns the current width of a bucket.
+func (a *Adaptive) Resolution() float64 {
+	if a.Scalar == nil || a.P.Buckets < 2 {
+		return 0.0
+	}
+	return a.Scalar.Range / float64(a.P.Buckets-1)
+}
+
+// Encode records a float64 value, adapts the mapping if needed, and
+// encodes the value to a bit vector.
+func (a *Adaptive) Encode(d interface{}) ([]bool, int) {
+	v, ok := d.(float64)
+	if !ok {
+		panic("Adaptive did not receive float64 input value!")
+	}
+
+	a.push(v)
+	a.adapt()
+	a.Iteration++
+
+	return a.Scalar.Encode(v)
+}
+
+// push adds a value to the window, evicting the oldest once full.
+func (a *Adaptive) push(v float64) {
+	switch {
+	case len(a.window) < a.P.Window:
+		a.window = append(a.window, v)
+	default:
+		a.window[a.idx] = v
+		a.idx = (a.idx + 1) % a.P.Window
+	}
+}
+
+// bounds returns the tracked range of the window.
+func (a *Adaptive) bounds() (float64, float64) {
+	if a.P.Quantile <= 0.0 {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, v := range a.window {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+		return lo, hi
+	}
+
+	sorted := make([]float64, len(a.window))
+	copy(sorted, a.window)
+	sort.Float64s(sorted)
+	k := int(a.P.Quantile * float64(len(sorted)-1))
+	return sorted[k], sorted[len(sorted)-1-k]
+}
+
+// adapt refits the mapping to the tracked range if the current one no
+// longer fits, and notifies listeners of the shift.
+func (a *Adaptive) adapt() {
+	lo, hi := a.bounds()
+	if a.Scalar != nil {
+		cur := a.Scalar.P
+		full := len(a.window) == a.P.Window
+		switch {
+		case lo < cur.Min || hi > cur.Max:
+		case full && hi-lo < a.P.Shrink*a.Scalar.Range:
+		default:
+			return
+		}
+	}
+
+	pad := a.P.Padding * (hi - lo)
+	next := ScalarParams{
+		Buckets: a.P.Buckets,
+		Min:     lo - pad,
+		Max:     hi + pad,
+		Active:  a.P.Active,
+	}
+
+	var old ScalarParams
+	if a.Scalar != nil {
+		old = a.Scalar.P
+	}
+	a.Scalar = NewScalar(next)
+
+	if old.Buckets == 0 {
+		return
+	}
+	s := Shift{Iteration: a.Iteration, Old: old, New: next}
+	for _, fn := range a.notify {
+		fn(s)
+	}
+}
+
+// Decode returns the float64 value at the center of the bucket that
+// best matches s under the current mapping.
+func (a *Adaptive) Decode(s []bool) interface{} {
+	if a.Scalar == nil {
+		return 0.0
+	}
+	return a.Scalar.Decode(s)
+}
@@ -0,0 +1,202 @@
+package enc
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// AudioParams represents a parameter set for an Audio encoder.
+type AudioParams struct {
+	SampleRate int          `json:"samplerate"` // samples per second
+	FrameSize  int          `json:"framesize"`  // samples per frame, power of 2
+	Bands      int          `json:"bands"`      // number of frequency bands
+	MinFreq    float64      `json:"minfreq"`    // lower edge of lowest band, Hz
+	MaxFreq    float64      `json:"maxfreq"`    // upper edge of highest band, Hz
+	Band       ScalarParams `json:"band"`       // per band encoding, in dB
+}
+
+// NewAudioParams returns a default param set, covering speech
+// frequencies at 16kHz with 32ms frames.
+func NewAudioParams() AudioParams {
+	return AudioParams{
+		SampleRate: 16000,
+		FrameSize:  512,
+		Bands:      16,
+		MinFreq:    80,
+		MaxFreq:    8000,
+		Band: ScalarParams{
+			Buckets: 24,
+			Min:     -80,
+			Max:     0,
+			Active:  8,
+			Wrap:    false,
+		},
+	}
+}
+
+// Audio encodes frames of PCM audio. Each frame is windowed and run
+// through an FFT, the power spectrum is summed into log spaced
+// frequency bands, and the level of each band in dB is encoded with
+// a Scalar sub-encoder. The band encodings are concatenated, lowest
+// frequency first.
+//
+// Samples are expected to be in [-1.0 : 1.0], so a full scale sine
+// wave concentrated in one band is encoded at roughly 0 dB.
+type Audio struct {
+	P      AudioParams `json:"params"`
+	Bits   int         `json:"bits"`
+	band   *Scalar
+	window []float64
+	norm   float64 // power normalization, from window energy
+	edges  []int
+}
+
+// NewAudio returns an Audio encoder initialized with the provided
+// AudioParams.
+func NewAudio(p AudioParams) *Audio {
+	if p.FrameSize < 2 || p.FrameSize&(p.FrameSize-1) != 0 {
+		panic("Audio frame size is not a power of 2 >= 2!")
+	}
+
+	a := &Audio{
+		P:      p,
+		band:   NewScalar(p.Band),
+		window: make([]float64, p.FrameSize),
+		edges:  make([]int, p.Bands+1),
+	}
+	a.Bits = p.Bands * a.band.Bits
+
+	// hann window
+	var energy float64
+	for i := range a.window {
+		a.window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(p.FrameSize-1))
+		energy += a.window[i] * a.window[i]
+	}
+
+	// a full scale sine has half its windowed power in the positive
+	// frequency bins, N/4 * energy in total
+	a.norm = 4.0 / (float64(p.FrameSize) * energy)
+
+	// log spaced band edges, as fft bin indices
+	hz := float64(p.SampleRate) / float64(p.FrameSize)
+	ratio := math.Log(p.MaxFreq / p.MinFreq)
+	for i := range a.edges {
+		f := p.MinFreq * math.Exp(ratio*float64(i)/float64(p.Bands))
+		a.edges[i] = int(f/hz + 0.5)
+		if a.edges[i] > p.FrameSize/2 {
+			a.edges[i] = p.FrameSize / 2
+		}
+		if i > 0 && a.edges[i] <= a.edges[i-1] {
+			a.edges[i] = a.edges[i-1] + 1
+		}
+	}
+
+	return a
+}
+
+// Levels returns the level of each frequency band in a frame, in dB.
+// Frames shorter than FrameSize are zero padded, longer frames are
+// truncated.
+func (a *Audio) Levels(frame []float32) []float64 {
+	x := make([]complex128, a.P.FrameSize)
+	for i := 0; i < len(x) && i < len(frame); i++ {
+		x[i] = complex(float64(frame[i])*a.window[i], 0)
+	}
+	fft(x)
+
+	levels := make([]float64, a.P.Bands)
+	for b := range levels {
+		var power float64
+		for k := a.edges[b]; k < a.edges[b+1] && k <= a.P.FrameSize/2; k++ {
+			mag := cmplx.Abs(x[k])
+			power += mag * mag * a.norm
+		}
+		levels[b] = 10 * math.Log10(power+1e-12)
+	}
+	return levels
+}
+
+// Encode encodes a []float32 frame to a bit vector. The returned
+// bucket index is the index of the loudest band.
+func (a *Audio) Encode(d interface{}) ([]bool, int) {
+	frame, ok := d.([]float32)
+	if !ok {
+		panic("Audio did not receive []float32 input value!")
+	}
+
+	levels := a.Levels(frame)
+	out := make([]bool, 0, a.Bits)
+
+	var loudest int
+	for b := range levels {
+		sv, _ := a.band.Encode(levels[b])
+		out = append(out, sv...)
+		if levels[b] > levels[loudest] {
+			loudest = b
+		}
+	}
+	return out, loudest
+}
+
+// Decode returns the decoded level of each band in dB, as a []float64.
+// Inputs shorter than Bits are zero padded.
+func (a *Audio) Decode(s []bool) interface{} {
+	if len(s) < a.Bits {
+		pad := make([]bool, a.Bits)
+		copy(pad, s)
+		s = pad
+	}
+
+	levels := make([]float64, a.P.Bands)
+	for b := range levels {
+		seg := s[b*a.band.Bits : (b+1)*a.band.Bits]
+		levels[b] = a.band.Decode(seg).(float64)
+	}
+	return levels
+}
+
+// Frames splits samples into frames of size samples, starting every
+// hop samples. A trailing partial frame is dropped. Size and hop must
+// be > 0.
+func Frames(samples []float32, size, hop int) [][]float32 {
+	if size <= 0 || hop <= 0 {
+		panic("Audio frame size and hop must be > 0!")
+	}
+
+	var frames [][]float32
+	for i := 0; i+size <= len(samples); i += hop {
+		frames = append(frames, samples[i:i+size])
+	}
+	return frames
+}
+
+// fft computes an in place radix-2 fast fourier transform of x, whose
+// length must be a power of 2.
+func fft(x []complex128) {
+	n := len(x)
+
+	// bit reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	// butterflies
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u, v := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = u+v, u-v
+				w *= step
+			}
+		}
+	}
+}
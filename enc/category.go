@@ -0,0 +1,170 @@
+package enc
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+
+	"github.com/nytopop/gohtm/vec"
+)
+
+// CategoryParams represents a parameter set for a Category encoder.
+type CategoryParams struct {
+	N      int   `json:"n"`      // output width
+	W      int   `json:"w"`      // active bits per category
+	Random bool  `json:"random"` // random distributed buckets
+	Grow   bool  `json:"grow"`   // add buckets for unseen categories
+	Seed   int64 `json:"seed"`
+}
+
+// NewCategoryParams returns a default param set.
+func NewCategoryParams() CategoryParams {
+	return CategoryParams{
+		N:      1024,
+		W:      32,
+		Random: false,
+		Grow:   true,
+		Seed:   1,
+	}
+}
+
+// Category encodes discrete symbols, either strings or ints, into
+// SDRs that share no semantic overlap.
+//
+// Bucket 0 is reserved for unknown categories. Every other bucket
+// is assigned to a category the first time it is encoded, unless
+// Grow is disabled or the encoder is out of space, in which case
+// unseen categories are encoded as unknown.
+//
+// By default buckets are contiguous and non-overlapping, which
+// limits capacity to N/W - 1 categories. With Random set, buckets
+// are W bits sampled at random from N, allowing any number of
+// categories at the cost of a small chance of overlap.
+type Category struct {
+	P          CategoryParams `json:"params"`
+	Categories []interface{}  `json:"categories"`
+	index      map[interface{}]int
+	bits       [][]int // random bucket bits, by bucket
+}
+
+// UnmarshalJSON decodes a Category, restoring int categories, which
+// encoding/json would otherwise decode as float64.
+func (c *Category) UnmarshalJSON(data []byte) error {
+	type category Category
+	var v category
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	for i := range v.Categories {
+		if f, ok := v.Categories[i].(float64); ok {
+			v.Categories[i] = int(f)
+		}
+	}
+	*c = Category(v)
+	return nil
+}
+
+// NewCategory returns a Category encoder initialized with the provided
+// CategoryParams.
+func NewCategory(p CategoryParams) *Category {
+	return &Category{
+		P:          p,
+		Categories: make([]interface{}, 0),
+		index:      make(map[interface{}]int),
+	}
+}
+
+// Copy returns a deep copy of c.
+func (c *Category) Copy() Encoder {
+	return &Category{
+		P:          c.P,
+		Categories: append([]interface{}{}, c.Categories...),
+	}
+}
+
+// Encode encodes a string or int category to a bit vector, and returns
+// the category's bucket index.
+func (c *Category) Encode(d interface{}) ([]bool, int) {
+	switch d.(type) {
+	case string, int:
+	default:
+		panic("Category did not receive string or int input value!")
+	}
+
+	b := c.Bucket(d)
+	if b == 0 && c.P.Grow && len(c.Categories) < c.capacity() {
+		c.Categories = append(c.Categories, d)
+		c.index[d] = len(c.Categories)
+		b = len(c.Categories)
+	}
+
+	return vec.ToBool(c.bucketBits(b), c.P.N), b
+}
+
+// Bucket returns the bucket index of a category, or 0 if the category
+// is unknown.
+func (c *Category) Bucket(d interface{}) int {
+	if c.index == nil {
+		c.index = make(map[interface{}]int)
+		for i := range c.Categories {
+			c.index[c.Categories[i]] = i + 1
+		}
+	}
+	return c.index[d]
+}
+
+// Buckets returns the number of buckets in use, including unknown.
+func (c *Category) Buckets() int {
+	return len(c.Categories) + 1
+}
+
+// Decode returns the category whose bucket best overlaps with s, or
+// nil if the unknown bucket is the best match.
+func (c *Category) Decode(s []bool) interface{} {
+	active := vec.ToInt(s)
+
+	var best, max int
+	for b := 0; b < c.Buckets(); b++ {
+		overlap := vec.Overlap(active, c.bucketBits(b))
+		if overlap > max {
+			best, max = b, overlap
+		}
+	}
+
+	if best == 0 {
+		return nil
+	}
+	return c.Categories[best-1]
+}
+
+// capacity returns the maximum number of categories that can be
+// assigned a bucket.
+func (c *Category) capacity() int {
+	if c.P.Random {
+		return int(^uint(0) >> 1)
+	}
+	return c.P.N/c.P.W - 1
+}
+
+// bucketBits returns the sorted indices of active bits for bucket b.
+// Random buckets are cached, and must not be modified.
+func (c *Category) bucketBits(b int) []int {
+	switch c.P.Random {
+	case true:
+		for len(c.bits) <= b {
+			rng := rand.New(rand.NewSource(c.P.Seed + int64(len(c.bits))))
+			bits := make([]int, c.P.W)
+			copy(bits, rng.Perm(c.P.N)[:c.P.W])
+			sort.Ints(bits)
+			c.bits = append(c.bits, bits)
+		}
+		return c.bits[b]
+	}
+
+	bits := make([]int, c.P.W)
+	for i := range bits {
+		bits[i] = b*c.P.W + i
+	}
+	return bits
+}
@@ -0,0 +1,250 @@
+package enc
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sort"
+)
+
+// CoordinateParams represents a parameter set for a Coordinate encoder.
+type CoordinateParams struct {
+	N       int   `json:"n"`       // output width
+	W       int   `json:"w"`       // active bits
+	Buckets int   `json:"buckets"` // bucket indices returned by Encode
+	Seed    int64 `json:"seed"`    // hash seed
+}
+
+// NewCoordinateParams returns a default param set.
+func NewCoordinateParams() CoordinateParams {
+	return CoordinateParams{
+		N:       1024,
+		W:       25,
+		Buckets: 1024,
+		Seed:    1,
+	}
+}
+
+// Point is an input value for a Coordinate encoder; an integer
+// coordinate in any number of dimensions, and the radius of the
+// neighborhood around it to encode.
+type Point struct {
+	Coords []int `json:"coords"`
+	Radius int   `json:"radius"`
+}
+
+// Coordinate encodes integer coordinates so that nearby coordinates
+// share active bits.
+//
+// Every coordinate within Radius of the input is assigned a random
+// but deterministic order by hashing it. Coordinates are then hashed
+// again in order to pick active bits, until W distinct bits are set.
+// Nearby inputs have overlapping neighborhoods, so they tend to select
+// many of the same coordinates, and so the same bits.
+//
+// Radius is raised to MinRadius if it is smaller, so that there are
+// enough coordinates to draw W bits from.
+//
+// Coordinate encodings cannot be reversed, so Decode returns nil.
+type Coordinate struct {
+	P CoordinateParams `json:"params"`
+}
+
+// NewCoordinate returns a Coordinate encoder initialized with the
+// provided CoordinateParams.
+func NewCoordinate(p CoordinateParams) *Coordinate {
+	if p.Buckets <= 0 {
+		panic("Coordinate buckets must be > 0!")
+	}
+
+	return &Coordinate{
+		P: p,
+	}
+}
+
+// MinRadius returns the smallest radius whose neighborhood holds at
+// least 2*W coordinates in dims dimensions, leaving spare coordinates
+// for those whose bits collide.
+func (c *Coordinate) MinRadius(dims int) int {
+	if dims <= 0 {
+		return 0
+	}
+	var r int
+	for math.Pow(float64(2*r+1), float64(dims)) < float64(2*c.P.W) {
+		r++
+	}
+	return r
+}
+
+// neighbor is a coordinate in a neighborhood, and its order.
+type neighbor struct {
+	coords []int
+	order  uint64
+}
+
+type neighbors []neighbor
+
+func (n neighbors) Len() int           { return len(n) }
+func (n neighbors) Less(i, j int) bool { return n[i].order > n[j].order }
+func (n neighbors) Swap(i, j int)      { n[i], n[j] = n[j], n[i] }
+
+// Encode encodes a Point to a bit vector. The returned bucket index is
+// a hash of the Point's center coordinate, in [0 : Buckets). Distinct
+// coordinates can share a bucket, so a classifier trained on it can
+// only tell apart roughly Buckets positions.
+func (c *Coordinate) Encode(v interface{}) ([]bool, int) {
+	p, ok := v.(Point)
+	if !ok {
+		panic("Coordinate did not receive Point input value!")
+	}
+
+	radius := p.Radius
+	if min := c.MinRadius(len(p.Coords)); radius < min {
+		radius = min
+	}
+
+	var nbs neighbors
+	c.neighborhood(p.Coords, radius, make([]int, 0, len(p.Coords)),
+		func(coords []int) {
+			nbs = append(nbs, neighbor{
+				coords: coords,
+				order:  c.hash(0, coords),
+			})
+		})
+	sort.Sort(nbs)
+
+	out := make([]bool, c.P.N)
+	for i, n := 0, 0; i < len(nbs) && n < c.P.W; i++ {
+		bit := c.hash(1, nbs[i].coords) % uint64(c.P.N)
+		if !out[bit] {
+			out[bit] = true
+			n++
+		}
+	}
+
+	return out, int(c.hash(2, p.Coords) % uint64(c.P.Buckets))
+}
+
+// neighborhood calls fn with every coordinate within radius of center,
+// one dimension at a time.
+func (c *Coordinate) neighborhood(center []int, radius int,
+	prefix []int, fn func([]int)) {
+
+	d := len(prefix)
+	if d == len(center) {
+		coords := make([]int, len(prefix))
+		copy(coords, prefix)
+		fn(coords)
+		return
+	}
+
+	for i := center[d] - radius; i <= center[d]+radius; i++ {
+		c.neighborhood(center, radius, append(prefix, i), fn)
+	}
+}
+
+// hash returns a seeded hash of coords, salted to derive independent
+// hashes for ordering and bit selection.
+func (c *Coordinate) hash(salt uint64, coords []int) uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+
+	binary.LittleEndian.PutUint64(buf, uint64(c.P.Seed))
+	h.Write(buf)
+	binary.LittleEndian.PutUint64(buf, salt)
+	h.Write(buf)
+	for _, x := range coords {
+		binary.LittleEndian.PutUint64(buf, uint64(x))
+		h.Write(buf)
+	}
+	return h.Sum64()
+}
+
+// Decode returns nil, as coordinate encodings cannot be reversed.
+func (c *Coordinate) Decode(s []bool) interface{} {
+	return nil
+}
+
+// GeoParams represents a parameter set for a Geo encoder.
+type GeoParams struct {
+	Coordinate CoordinateParams `json:"coordinate"`
+	Scale      float64          `json:"scale"`    // meters per coordinate
+	Timestep   float64          `json:"timestep"` // seconds between inputs
+}
+
+// NewGeoParams returns a default param set, suited to positions
+// sampled every second at walking to driving speeds.
+func NewGeoParams() GeoParams {
+	return GeoParams{
+		Coordinate: NewCoordinateParams(),
+		Scale:      30,
+		Timestep:   1,
+	}
+}
+
+// GeoPoint is an input value for a Geo encoder; a position in degrees
+// and a speed in meters per second.
+type GeoPoint struct {
+	Lat   float64 `json:"lat"`
+	Lon   float64 `json:"lon"`
+	Speed float64 `json:"speed"`
+}
+
+const (
+	// earthRadius is the WGS84 equatorial radius, in meters.
+	earthRadius = 6378137.0
+
+	// maxLat is the latitude at which the mercator projection is cut
+	// off, as it diverges at the poles.
+	maxLat = 85.05112878
+)
+
+// Geo encodes latitude / longitude positions, by projecting them onto
+// a grid of Scale meter coordinates and encoding the coordinates with
+// a Coordinate encoder. The neighborhood radius grows with speed, so
+// fast moving positions overlap with positions further away.
+type Geo struct {
+	P GeoParams `json:"params"`
+}
+
+// NewGeo returns a Geo encoder initialized with the provided GeoParams.
+func NewGeo(p GeoParams) *Geo {
+	return &Geo{
+		P: p,
+	}
+}
+
+// coordinate returns the Coordinate encoder underlying g.
+func (g *Geo) coordinate() *Coordinate {
+	return NewCoordinate(g.P.Coordinate)
+}
+
+// Point projects a GeoPoint onto the coordinate grid, with the
+// mercator projection, and computes its radius from its speed.
+// Latitudes are clamped to +/- 85.05 degrees, where the projection
+// is square.
+func (g *Geo) Point(p GeoPoint) Point {
+	lat := math.Max(-maxLat, math.Min(maxLat, p.Lat)) * math.Pi / 180
+	lon := p.Lon * math.Pi / 180
+	x := earthRadius * lon
+	y := earthRadius * math.Log(math.Tan(math.Pi/4+lat/2))
+
+	return Point{
+		Coords: []int{int(x / g.P.Scale), int(y / g.P.Scale)},
+		Radius: int(math.Floor(p.Speed/2*g.P.Timestep/g.P.Scale + 0.5)),
+	}
+}
+
+// Encode encodes a GeoPoint to a bit vector.
+func (g *Geo) Encode(v interface{}) ([]bool, int) {
+	p, ok := v.(GeoPoint)
+	if !ok {
+		panic("Geo did not receive GeoPoint input value!")
+	}
+	return g.coordinate().Encode(g.Point(p))
+}
+
+// Decode returns nil, as coordinate encodings cannot be reversed.
+func (g *Geo) Decode(s []bool) interface{} {
+	return nil
+}
@@ -0,0 +1,222 @@
+package enc
+
+import "time"
+
+// DateTimeField configures one sub-encoding of a DateTime encoder.
+// W is the number of active bits, Buckets the number of distinct
+// values the field can take. A W of 0 disables the field.
+type DateTimeField struct {
+	W       int `json:"w"`
+	Buckets int `json:"buckets"`
+}
+
+// Holiday is a day of the year, repeating annually.
+type Holiday struct {
+	Month time.Month `json:"month"`
+	Day   int        `json:"day"`
+}
+
+// DateTimeParams represents a parameter set for a DateTime encoder.
+type DateTimeParams struct {
+	TimeOfDay DateTimeField `json:"timeofday"` // periodic over 24 hours
+	DayOfWeek DateTimeField `json:"dayofweek"` // periodic over 7 days
+	Weekend   DateTimeField `json:"weekend"`   // saturday or sunday
+	Season    DateTimeField `json:"season"`    // periodic over 1 year
+	Holiday   DateTimeField `json:"holiday"`   // date is in Holidays
+	Holidays  []Holiday     `json:"holidays"`
+}
+
+// NewDateTimeParams returns a default param set, with about 2.6% of
+// bits active. Times of day share bits within 30 minutes, days of the
+// week within 3.5 hours, and seasons within 8 days; the weekend flag
+// is a separate field.
+func NewDateTimeParams() DateTimeParams {
+	return DateTimeParams{
+		TimeOfDay: DateTimeField{W: 21, Buckets: 1024},
+		DayOfWeek: DateTimeField{W: 11, Buckets: 512},
+		Weekend:   DateTimeField{W: 11, Buckets: 2},
+		Season:    DateTimeField{W: 11, Buckets: 512},
+		Holiday:   DateTimeField{W: 0, Buckets: 2},
+		Holidays:  make([]Holiday, 0),
+	}
+}
+
+// DateTimeBuckets contains the bucket index of each field of a
+// DateTime encoding. Disabled fields have a bucket index of -1.
+type DateTimeBuckets struct {
+	TimeOfDay int `json:"timeofday"`
+	DayOfWeek int `json:"dayofweek"`
+	Weekend   int `json:"weekend"`
+	Season    int `json:"season"`
+	Holiday   int `json:"holiday"`
+}
+
+// DateTime encodes a time.Time as the concatenation of several
+// sub-encodings, so that times sharing a daily, weekly or yearly
+// phase share active bits.
+//
+// A periodic field is Buckets bits wide, with W contiguous bits active
+// that shift by one bit per bucket, so times less than W buckets apart
+// share bits. Keep Buckets much larger than W for a sparse encoding.
+//
+// Periodic fields wrap around, so 23:45 overlaps with 00:15. The
+// weekend and holiday fields are flags with two non-overlapping
+// buckets, false and true.
+type DateTime struct {
+	P DateTimeParams `json:"params"`
+}
+
+// NewDateTime returns a DateTime encoder initialized with the provided
+// DateTimeParams.
+func NewDateTime(p DateTimeParams) *DateTime {
+	return &DateTime{
+		P: p,
+	}
+}
+
+// dtField is a single enabled field of a DateTime encoder.
+type dtField struct {
+	f      DateTimeField
+	wrap   bool
+	bucket *int
+}
+
+// fields returns the enabled fields in encoding order. If b is not
+// nil, each field's bucket points into b.
+func (d *DateTime) fields(b *DateTimeBuckets) []dtField {
+	if b == nil {
+		b = &DateTimeBuckets{}
+	}
+	all := []dtField{
+		{d.P.TimeOfDay, true, &b.TimeOfDay},
+		{d.P.DayOfWeek, true, &b.DayOfWeek},
+		{d.P.Weekend, false, &b.Weekend},
+		{d.P.Season, true, &b.Season},
+		{d.P.Holiday, false, &b.Holiday},
+	}
+
+	fields := make([]dtField, 0, len(all))
+	for _, f := range all {
+		if f.f.W > 0 {
+			fields = append(fields, f)
+		} else {
+			*f.bucket = -1
+		}
+	}
+	return fields
+}
+
+// width returns the number of bits a field occupies.
+func (f dtField) width() int {
+	if f.wrap {
+		return f.f.Buckets
+	}
+	return f.f.W * f.f.Buckets
+}
+
+// Size returns the total number of bits in an encoding.
+func (d *DateTime) Size() int {
+	var n int
+	for _, f := range d.fields(nil) {
+		n += f.width()
+	}
+	return n
+}
+
+// Buckets returns the bucket index of each field for t.
+func (d *DateTime) Buckets(t time.Time) DateTimeBuckets {
+	var b DateTimeBuckets
+	day := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
+	week := float64(t.Weekday()) + day/24
+	year := float64(t.YearDay()-1) + day/24
+
+	b.TimeOfDay = periodicBucket(day/24, d.P.TimeOfDay.Buckets)
+	b.DayOfWeek = periodicBucket(week/7, d.P.DayOfWeek.Buckets)
+	b.Season = periodicBucket(year/366, d.P.Season.Buckets)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		b.Weekend = 1
+	}
+	for _, h := range d.P.Holidays {
+		if t.Month() == h.Month && t.Day() == h.Day {
+			b.Holiday = 1
+		}
+	}
+
+	d.fields(&b) // marks disabled fields with -1
+	return b
+}
+
+// periodicBucket maps a phase in [0, 1) to one of n buckets.
+func periodicBucket(phase float64, n int) int {
+	b := int(phase * float64(n))
+	switch {
+	case b >= n:
+		b = n - 1
+	case b < 0:
+		b = 0
+	}
+	return b
+}
+
+// Encode encodes a time.Time to a bit vector. The returned bucket index
+// combines the bucket indices of all enabled fields.
+func (d *DateTime) Encode(v interface{}) ([]bool, int) {
+	t, ok := v.(time.Time)
+	if !ok {
+		panic("DateTime did not receive time.Time input value!")
+	}
+
+	b := d.Buckets(t)
+	out := make([]bool, d.Size())
+
+	var offset, idx int
+	for _, f := range d.fields(&b) {
+		for j := 0; j < f.f.W; j++ {
+			switch f.wrap {
+			case true:
+				out[offset+(*f.bucket+j)%f.f.Buckets] = true
+			case false:
+				out[offset+*f.bucket*f.f.W+j] = true
+			}
+		}
+		offset += f.width()
+		idx = idx*f.f.Buckets + *f.bucket
+	}
+
+	return out, idx
+}
+
+// Decode returns the DateTimeBuckets that best match s, field by field.
+func (d *DateTime) Decode(s []bool) interface{} {
+	var b DateTimeBuckets
+
+	var offset int
+	for _, f := range d.fields(&b) {
+		seg := s[offset : offset+f.width()]
+
+		var best, max int
+		for k := 0; k < f.f.Buckets; k++ {
+			var overlap int
+			for j := 0; j < f.f.W; j++ {
+				switch f.wrap {
+				case true:
+					if seg[(k+j)%f.f.Buckets] {
+						overlap++
+					}
+				case false:
+					if seg[k*f.f.W+j] {
+						overlap++
+					}
+				}
+			}
+			if overlap > max {
+				best, max = k, overlap
+			}
+		}
+
+		*f.bucket = best
+		offset += f.width()
+	}
+
+	return b
+}
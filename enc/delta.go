@@ -0,0 +1,84 @@
+package enc
+
+// Delta wraps another encoder to encode the change in a float64 value
+// since the previous call to Encode, rather than the value itself.
+//
+// If Absolute is set, the absolute value is encoded with it and the
+// encoding appended after the delta encoding, so both the value and
+// its rate of change are represented.
+//
+// The first value after creation or Reset has no predecessor, and is
+// encoded as a delta of 0.0. Deltas can be negative, so Inner must
+// accept negative values; a Scalar with Min < 0 works well.
+type Delta struct {
+	Inner    Encoder `json:"inner"`
+	Absolute Encoder `json:"absolute"`
+	prev     float64
+	primed   bool
+	width    int
+}
+
+// NewDelta returns a Delta encoder that encodes deltas with inner, and
+// absolute values with absolute if it is not nil.
+func NewDelta(inner, absolute Encoder) *Delta {
+	return &Delta{
+		Inner:    inner,
+		Absolute: absolute,
+	}
+}
+
+// Reset forgets the previous value, so the next value is not compared
+// against one from an unrelated sequence.
+func (d *Delta) Reset() {
+	d.prev = 0.0
+	d.primed = false
+}
+
+// Copy returns a deep copy of d, including its previous value.
+func (d *Delta) Copy() Encoder {
+	c := *d
+	c.Inner = Copy(d.Inner)
+	if d.Absolute != nil {
+		c.Absolute = Copy(d.Absolute)
+	}
+	return &c
+}
+
+// Encode encodes the change in a float64 value since the last call to
+// Encode, and returns the bucket index of the delta.
+func (d *Delta) Encode(v interface{}) ([]bool, int) {
+	x, ok := v.(float64)
+	if !ok {
+		panic("Delta did not receive float64 input value!")
+	}
+
+	var delta float64
+	if d.primed {
+		delta = x - d.prev
+	}
+	d.prev, d.primed = x, true
+
+	out, b := d.Inner.Encode(delta)
+	d.width = len(out)
+	if d.Absolute != nil {
+		abs, _ := d.Absolute.Encode(x)
+		out = append(out, abs...)
+	}
+	return out, b
+}
+
+// Decode decodes the delta portion of s, and returns the value it
+// implies when applied to the last encoded value. If the inner encoder
+// does not decode to a float64, its decoded value is returned as is.
+func (d *Delta) Decode(s []bool) interface{} {
+	if d.width > 0 && d.width < len(s) {
+		s = s[:d.width]
+	}
+
+	switch delta := d.Inner.Decode(s).(type) {
+	case float64:
+		return d.prev + delta
+	default:
+		return delta
+	}
+}
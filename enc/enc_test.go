@@ -0,0 +1,736 @@
+package enc_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/enctest"
+	"github.com/nytopop/gohtm/vec"
+)
+
+func TestScalar(t *testing.T) {
+	p := enctest.NewParams(enctest.Float64s(0, 65))
+	if err := enctest.Check(enc.NewScalar(enc.NewScalarParams()), p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScalarBuckets(t *testing.T) {
+	for _, c := range []struct {
+		p      enc.ScalarParams
+		in     interface{}
+		bucket int
+		out    float64
+	}{
+		{enc.ScalarParams{Buckets: 11, Min: 0, Max: 1, Active: 3}, 0.5, 5, 0.5},
+		{enc.ScalarParams{Buckets: 11, Min: 0, Max: 1, Active: 3}, 0.04, 0, 0},
+		{enc.ScalarParams{Buckets: 11, Min: 0, Max: 1, Active: 3}, -3.0, 0, 0},
+		{enc.ScalarParams{Buckets: 11, Min: 0, Max: 1, Active: 3}, 7, 10, 1},
+		{enc.ScalarParams{Buckets: 5, Min: -10, Max: 10, Active: 3}, -5, 1, -5},
+		{enc.ScalarParams{Buckets: 8, Min: 0, Max: 24, Active: 3, Wrap: true}, 3.0, 1, 3},
+		{enc.ScalarParams{Buckets: 8, Min: 0, Max: 24, Active: 3, Wrap: true}, 24.0, 0, 0},
+		{enc.ScalarParams{Buckets: 8, Min: 0, Max: 24, Active: 3, Wrap: true}, -3.0, 7, 21},
+	} {
+		s := enc.NewScalar(c.p)
+		out, b := s.Encode(c.in)
+		if b != c.bucket {
+			t.Errorf("%+v: %v in bucket %d, want %d", c.p, c.in, b, c.bucket)
+		}
+		if n := len(vec.ToInt(out)); len(out) != s.Bits || n != c.p.Active {
+			t.Errorf("%+v: %d of %d bits active, want %d of %d", c.p, n, len(out), c.p.Active, s.Bits)
+		}
+		if d := s.Decode(out).(float64); d != c.out {
+			t.Errorf("%+v: %v decoded to %v, want %v", c.p, c.in, d, c.out)
+		}
+	}
+
+	// the highest wrapped bucket shares bits with the lowest
+	s := enc.NewScalar(enc.ScalarParams{Buckets: 8, Min: 0, Max: 24, Active: 3, Wrap: true})
+	lo, _ := s.Encode(0.0)
+	hi, _ := s.Encode(21.0)
+	if s.Bits != 8 || vec.Overlap(vec.ToInt(lo), vec.ToInt(hi)) != 2 {
+		t.Fatalf("wrapped encodings %v and %v", vec.ToInt(lo), vec.ToInt(hi))
+	}
+}
+
+func TestRDScalar(t *testing.T) {
+	p := enctest.NewParams(enctest.Float64s(0, 100))
+	if err := enctest.Check(enc.NewRDScalar(1024, 40, 0, 1), p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCategory(t *testing.T) {
+	names := []interface{}{"a", "b", "c", "d", 1, 2, 3, 4}
+	p := enctest.NewParams(func(rng *rand.Rand) interface{} {
+		return names[rng.Intn(len(names))]
+	})
+	p.Distance = func(a, b interface{}) float64 {
+		if a == b {
+			return 0
+		}
+		return 1
+	}
+	p.Resolution = 0
+
+	for _, random := range []bool{false, true} {
+		cp := enc.NewCategoryParams()
+		cp.Random = random
+		if err := enctest.Check(enc.NewCategory(cp), p); err != nil {
+			t.Fatalf("random=%v: %v", random, err)
+		}
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	// times within one day, so semantic distance is time of day
+	day := time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC)
+	p := enctest.NewParams(func(rng *rand.Rand) interface{} {
+		return day.Add(time.Duration(rng.Int63n(int64(24 * time.Hour))))
+	})
+	p.Distance = func(a, b interface{}) float64 {
+		d := math.Abs(a.(time.Time).Sub(b.(time.Time)).Hours())
+		return math.Min(d, 24-d)
+	}
+	p.SkipDecode = true // decodes to DateTimeBuckets
+
+	if err := enctest.Check(enc.NewDateTime(enc.NewDateTimeParams()), p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDateTimeSparsity(t *testing.T) {
+	d := enc.NewDateTime(enc.NewDateTimeParams())
+	out, _ := d.Encode(time.Date(2017, time.March, 1, 12, 0, 0, 0, time.UTC))
+	if s := vec.Sparsity(out); s > 0.03 {
+		t.Fatalf("default encoding is %.1f%% dense", s*100)
+	}
+}
+
+func TestDateTimeHoliday(t *testing.T) {
+	p := enc.NewDateTimeParams()
+	p.Holiday.W = 11
+	p.Holidays = []enc.Holiday{{Month: time.December, Day: 25}}
+	d := enc.NewDateTime(p)
+
+	for _, c := range []struct {
+		t       time.Time
+		holiday int
+	}{
+		{time.Date(2017, time.December, 24, 12, 0, 0, 0, time.UTC), 0},
+		{time.Date(2017, time.December, 25, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2017, time.December, 25, 23, 59, 0, 0, time.UTC), 1},
+		{time.Date(2018, time.December, 25, 12, 0, 0, 0, time.UTC), 1},
+		{time.Date(2017, time.December, 26, 12, 0, 0, 0, time.UTC), 0},
+	} {
+		if b := d.Buckets(c.t); b.Holiday != c.holiday {
+			t.Errorf("%v: holiday bucket %d, want %d", c.t, b.Holiday, c.holiday)
+		}
+	}
+
+	// the holiday field is last, W bits per bucket
+	eve, _ := d.Encode(time.Date(2017, time.December, 24, 12, 0, 0, 0, time.UTC))
+	day, _ := d.Encode(time.Date(2017, time.December, 25, 12, 0, 0, 0, time.UTC))
+	n := d.Size()
+	hol := n - 2*p.Holiday.W
+	if vec.Overlap(vec.ToInt(eve[hol:]), vec.ToInt(day[hol:])) != 0 {
+		t.Fatal("holiday and non holiday share holiday bits")
+	}
+	if len(vec.ToInt(day[n-p.Holiday.W:])) != p.Holiday.W {
+		t.Fatal("holiday did not set the true bucket")
+	}
+}
+
+func TestDateTimeDecode(t *testing.T) {
+	p := enc.NewDateTimeParams()
+	p.Holiday.W = 11
+	p.Holidays = []enc.Holiday{{Month: time.July, Day: 4}}
+	d := enc.NewDateTime(p)
+
+	for _, tm := range []time.Time{
+		time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2017, time.March, 1, 13, 37, 0, 0, time.UTC),
+		time.Date(2017, time.July, 4, 8, 15, 0, 0, time.UTC),
+		time.Date(2017, time.October, 14, 23, 59, 0, 0, time.UTC),
+		time.Date(2016, time.December, 31, 18, 0, 0, 0, time.UTC),
+	} {
+		out, _ := d.Encode(tm)
+		got := d.Decode(out).(enc.DateTimeBuckets)
+		if want := d.Buckets(tm); got != want {
+			t.Errorf("%v: decoded %+v, want %+v", tm, got, want)
+		}
+	}
+
+	// disabled fields decode to -1
+	p = enc.NewDateTimeParams()
+	d = enc.NewDateTime(p)
+	out, _ := d.Encode(time.Date(2017, time.July, 4, 8, 15, 0, 0, time.UTC))
+	if b := d.Decode(out).(enc.DateTimeBuckets); b.Holiday != -1 {
+		t.Errorf("disabled holiday field decoded to %d", b.Holiday)
+	}
+}
+
+func TestCoordinate(t *testing.T) {
+	p := enctest.NewParams(func(rng *rand.Rand) interface{} {
+		return enc.Point{
+			Coords: []int{rng.Intn(64), rng.Intn(64)},
+			Radius: 4,
+		}
+	})
+	p.Distance = func(a, b interface{}) float64 {
+		x, y := a.(enc.Point).Coords, b.(enc.Point).Coords
+		return math.Hypot(float64(x[0]-y[0]), float64(x[1]-y[1]))
+	}
+	p.SkipDecode = true // not reversible
+
+	if err := enctest.Check(enc.NewCoordinate(enc.NewCoordinateParams()), p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAudio(t *testing.T) {
+	ap := enc.NewAudioParams()
+
+	// full scale sines, with frequency a log distance apart
+	p := enctest.NewParams(func(rng *rand.Rand) interface{} {
+		f := ap.MinFreq * math.Pow(ap.MaxFreq/ap.MinFreq, rng.Float64())
+		frame := make([]float32, ap.FrameSize)
+		for i := range frame {
+			frame[i] = float32(math.Sin(2 * math.Pi * f * float64(i) / float64(ap.SampleRate)))
+		}
+		return frame
+	})
+	p.Distance = func(a, b interface{}) float64 {
+		return math.Abs(math.Log2(peak(a.([]float32), ap) / peak(b.([]float32), ap)))
+	}
+	p.SkipDecode = true // decodes to band levels
+
+	if err := enctest.Check(enc.NewAudio(ap), p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// peak returns the frequency of a sine frame, from its zero crossings.
+func peak(frame []float32, p enc.AudioParams) float64 {
+	var n int
+	for i := 1; i < len(frame); i++ {
+		if (frame[i-1] < 0) != (frame[i] < 0) {
+			n++
+		}
+	}
+	return float64(n) / 2 * float64(p.SampleRate) / float64(len(frame))
+}
+
+func TestCategoryJSON(t *testing.T) {
+	for _, random := range []bool{false, true} {
+		p := enc.NewCategoryParams()
+		p.Random = random
+		c := enc.NewCategory(p)
+		c.Encode("a")
+		c.Encode(7)
+		c.Encode("b")
+		before, b := c.Encode(7)
+
+		buf, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var d enc.Category
+		if err := json.Unmarshal(buf, &d); err != nil {
+			t.Fatal(err)
+		}
+
+		after, db := d.Encode(7)
+		if db != b || !vec.Equal(vec.ToInt(after), vec.ToInt(before)) {
+			t.Fatalf("random=%v: bucket %d after round trip, want %d", random, db, b)
+		}
+		if got := d.Decode(after); got != 7 {
+			t.Fatalf("random=%v: decoded %v (%T), want 7", random, got, got)
+		}
+		if d.Buckets() != 4 {
+			t.Fatalf("random=%v: %d buckets after round trip, want 4", random, d.Buckets())
+		}
+	}
+}
+
+func TestMultiEncoderJSON(t *testing.T) {
+	m := enc.NewMultiEncoder()
+	m.Add("value", enc.NewScalar(enc.NewScalarParams()), 75)
+	m.Add("kind", enc.NewCategory(enc.NewCategoryParams()), 1024)
+	rec := map[string]interface{}{"value": 12.0, "kind": "a"}
+	before, _ := m.Encode(rec)
+
+	buf, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n enc.MultiEncoder
+	if err := json.Unmarshal(buf, &n); err != nil {
+		t.Fatal(err)
+	}
+	n.SetEncoder("value", m.Fields[0].Encoder)
+	n.SetEncoder("kind", m.Fields[1].Encoder)
+
+	after, _ := n.Encode(rec)
+	if n.Target != "value" || !vec.Equal(vec.ToInt(after), vec.ToInt(before)) {
+		t.Fatal("encoding differs after round trip")
+	}
+}
+
+func TestMultiEncoderFields(t *testing.T) {
+	cp := enc.NewCategoryParams()
+	cp.N, cp.W = 64, 8
+	dt := enc.NewDateTime(enc.NewDateTimeParams())
+
+	m := enc.NewMultiEncoder()
+	m.Add("kind", enc.NewCategory(cp), 64)
+	m.Add("time", dt, dt.Size())
+	m.Add("tag", enc.NewCategory(cp), 64)
+
+	for i, c := range []struct {
+		name          string
+		offset, width int
+	}{
+		{"kind", 0, 64},
+		{"time", 64, dt.Size()},
+		{"tag", 64 + dt.Size(), 64},
+	} {
+		f, ok := m.Field(c.name)
+		if !ok || f.Offset != c.offset || f.Width != c.width || m.Fields[i].Name != c.name {
+			t.Fatalf("field %q at %d+%d, want %d+%d", c.name, f.Offset, f.Width, c.offset, c.width)
+		}
+	}
+	if m.Size() != 128+dt.Size() {
+		t.Fatalf("size %d, want %d", m.Size(), 128+dt.Size())
+	}
+	if _, ok := m.Field("missing"); ok {
+		t.Fatal("found a field that was never added")
+	}
+
+	when := time.Date(2017, time.March, 1, 13, 37, 0, 0, time.UTC)
+	recs := []map[string]interface{}{
+		{"kind": "a", "time": when, "tag": 1},
+		{"kind": "b", "time": when.Add(36 * time.Hour), "tag": 2},
+		{"kind": "a", "time": when.Add(-5 * time.Hour), "tag": 2},
+	}
+	for _, rec := range recs {
+		out, buckets := m.EncodeFields(rec)
+
+		// every field's bits are its sub-encoding, at its offset
+		for _, f := range m.Fields {
+			sv, b := f.Encoder.Encode(rec[f.Name])
+			if buckets[f.Name] != b {
+				t.Errorf("%v: %s bucket %d, want %d", rec, f.Name, buckets[f.Name], b)
+			}
+			if !vec.Equal(vec.ToInt(out[f.Offset:f.Offset+f.Width]), vec.ToInt(sv)) {
+				t.Errorf("%v: %s bits differ from its sub-encoding", rec, f.Name)
+			}
+		}
+		if _, b := m.Encode(rec); b != buckets["kind"] {
+			t.Errorf("%v: Encode returned bucket %d, want target bucket %d", rec, b, buckets["kind"])
+		}
+
+		got := m.Decode(out).(map[string]interface{})
+		if got["kind"] != rec["kind"] || got["tag"] != rec["tag"] {
+			t.Errorf("%v: decoded %v", rec, got)
+		}
+		if got["time"] != dt.Buckets(rec["time"].(time.Time)) {
+			t.Errorf("%v: decoded time %v, want %v", rec, got["time"], dt.Buckets(rec["time"].(time.Time)))
+		}
+	}
+}
+
+func TestCoordinateActive(t *testing.T) {
+	c := enc.NewCoordinate(enc.NewCoordinateParams())
+
+	rng := rand.New(rand.NewSource(1))
+	for _, dims := range []int{1, 2, 3} {
+		for _, radius := range []int{0, 1, c.MinRadius(dims), 6} {
+			for i := 0; i < 64; i++ {
+				pt := enc.Point{Coords: make([]int, dims), Radius: radius}
+				for d := range pt.Coords {
+					pt.Coords[d] = rng.Intn(1<<20) - 1<<19
+				}
+				out, _ := c.Encode(pt)
+				if n := len(vec.ToInt(out)); n != c.P.W {
+					t.Fatalf("%d dims, radius %d: %d active bits, want %d", dims, radius, n, c.P.W)
+				}
+			}
+		}
+	}
+
+	// radii below the minimum encode as the minimum
+	pt := enc.Point{Coords: []int{3, 4}, Radius: 0}
+	a, _ := c.Encode(pt)
+	pt.Radius = c.MinRadius(2)
+	b, _ := c.Encode(pt)
+	if !vec.Equal(vec.ToInt(a), vec.ToInt(b)) {
+		t.Fatal("radius 0 was not raised to the minimum radius")
+	}
+}
+
+func TestGeoPoles(t *testing.T) {
+	g := enc.NewGeo(enc.NewGeoParams())
+
+	for _, lat := range []float64{-90, 90} {
+		pt := g.Point(enc.GeoPoint{Lat: lat, Lon: 10})
+		edge := g.Point(enc.GeoPoint{Lat: math.Copysign(85.05112878, lat), Lon: 10})
+		if pt.Coords[1] != edge.Coords[1] {
+			t.Fatalf("lat %v projected to %v, want %v", lat, pt.Coords, edge.Coords)
+		}
+		if out, _ := g.Encode(enc.GeoPoint{Lat: lat, Lon: 10}); len(vec.ToInt(out)) != g.P.Coordinate.W {
+			t.Fatalf("lat %v: %d active bits", lat, len(vec.ToInt(out)))
+		}
+	}
+
+	// nearby points overlap, distant ones mostly do not
+	a, _ := g.Encode(enc.GeoPoint{Lat: 45, Lon: 10})
+	b, _ := g.Encode(enc.GeoPoint{Lat: 45.0002, Lon: 10})
+	c, _ := g.Encode(enc.GeoPoint{Lat: 46, Lon: 10})
+	near := vec.Overlap(vec.ToInt(a), vec.ToInt(b))
+	far := vec.Overlap(vec.ToInt(a), vec.ToInt(c))
+	if near <= far {
+		t.Fatalf("overlap %d with a nearby point, %d with a distant one", near, far)
+	}
+}
+
+func TestCoordinateBuckets(t *testing.T) {
+	p := enc.NewCoordinateParams()
+	p.Buckets = 16
+	c := enc.NewCoordinate(p)
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 256; i++ {
+		pt := enc.Point{Coords: []int{rng.Int(), -rng.Int()}, Radius: 2}
+		if _, b := c.Encode(pt); b < 0 || b >= p.Buckets {
+			t.Fatalf("bucket %d for %v, want [0 : %d)", b, pt.Coords, p.Buckets)
+		}
+	}
+}
+
+func TestAudioLevels(t *testing.T) {
+	p := enc.NewAudioParams()
+	a := enc.NewAudio(p)
+
+	// a full scale sine concentrated in one band reads roughly 0 dB
+	for _, f := range []float64{250, 1000, 4000} {
+		frame := make([]float32, p.FrameSize)
+		for i := range frame {
+			frame[i] = float32(math.Sin(2 * math.Pi * f * float64(i) / float64(p.SampleRate)))
+		}
+
+		max := math.Inf(-1)
+		for _, l := range a.Levels(frame) {
+			max = math.Max(max, l)
+		}
+		if max < -1.5 || max > 0.5 {
+			t.Fatalf("%v Hz: loudest band at %.2f dB, want ~0 dB", f, max)
+		}
+	}
+}
+
+func TestAudioValidates(t *testing.T) {
+	for _, size := range []int{-2, 0, 1, 3, 500} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("frame size %d did not panic", size)
+				}
+			}()
+			p := enc.NewAudioParams()
+			p.FrameSize = size
+			enc.NewAudio(p)
+		}()
+	}
+}
+
+func TestAudioDecodeShort(t *testing.T) {
+	a := enc.NewAudio(enc.NewAudioParams())
+	for _, n := range []int{0, 1, a.Bits / 2, a.Bits} {
+		if levels := a.Decode(make([]bool, n)).([]float64); len(levels) != a.P.Bands {
+			t.Fatalf("%d bit input decoded to %d bands, want %d", n, len(levels), a.P.Bands)
+		}
+	}
+}
+
+// wav returns a PCM RIFF WAVE file holding data.
+func wav(channels, rate, bits int, data []byte) []byte {
+	var buf bytes.Buffer
+	le := func(v interface{}) { binary.Write(&buf, binary.LittleEndian, v) }
+
+	buf.WriteString("RIFF")
+	le(uint32(4 + 8 + 16 + 8 + len(data)))
+	buf.WriteString("WAVEfmt ")
+	le(uint32(16))
+	le(uint16(1))
+	le(uint16(channels))
+	le(uint32(rate))
+	le(uint32(rate * channels * bits / 8))
+	le(uint16(channels * bits / 8))
+	le(uint16(bits))
+	buf.WriteString("data")
+	le(uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestReadWAV(t *testing.T) {
+	pcm16 := func(s ...int16) []byte {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, s)
+		return buf.Bytes()
+	}
+
+	for _, c := range []struct {
+		name    string
+		file    []byte
+		rate    int
+		samples []float32
+	}{
+		{"8 bit mono", wav(1, 8000, 8, []byte{128, 192, 0, 64}),
+			8000, []float32{0, 0.5, -1, -0.5}},
+		{"16 bit mono", wav(1, 16000, 16, pcm16(0, 16384, -32768, -8192)),
+			16000, []float32{0, 0.5, -1, -0.25}},
+		{"16 bit stereo", wav(2, 44100, 16, pcm16(16384, 16384, -32768, 0)),
+			44100, []float32{0.5, -0.5}},
+		{"16 bit odd byte", wav(1, 16000, 16, append(pcm16(16384), 0xff)),
+			16000, []float32{0.5}},
+	} {
+		samples, rate, err := enc.ReadWAV(bytes.NewReader(c.file))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if rate != c.rate || len(samples) != len(c.samples) {
+			t.Errorf("%s: %d samples at %d Hz, want %d at %d Hz",
+				c.name, len(samples), rate, len(c.samples), c.rate)
+			continue
+		}
+		for i := range samples {
+			if samples[i] != c.samples[i] {
+				t.Errorf("%s: samples %v, want %v", c.name, samples, c.samples)
+				break
+			}
+		}
+	}
+
+	file := wav(1, 8000, 8, []byte{128, 128})
+	for _, c := range []struct {
+		name string
+		file []byte
+	}{
+		{"empty", nil},
+		{"truncated riff header", file[:10]},
+		{"not wave", append([]byte("RIFF\x00\x00\x00\x00AVI "), file[12:]...)},
+		{"truncated fmt chunk", file[:12+8+8]},
+		{"missing data chunk", file[:12+8+16]},
+		{"unsupported sample size", wav(1, 8000, 24, []byte{0, 0, 0})},
+	} {
+		if _, _, err := enc.ReadWAV(bytes.NewReader(c.file)); err == nil {
+			t.Errorf("%s: no error", c.name)
+		}
+	}
+}
+
+func TestAdaptive(t *testing.T) {
+	ap := func(window int, quantile, padding, shrink float64) enc.AdaptiveParams {
+		return enc.AdaptiveParams{
+			Buckets:  11,
+			Active:   3,
+			Window:   window,
+			Quantile: quantile,
+			Padding:  padding,
+			Shrink:   shrink,
+		}
+	}
+
+	for _, c := range []struct {
+		name     string
+		p        enc.AdaptiveParams
+		stream   []float64
+		min, max float64
+		shifts   []int // iterations of each shift
+	}{
+		{"grows", ap(100, 0, 0, 0.5), []float64{0, 1, 2, 3},
+			0, 3, []int{1, 2, 3}},
+		{"padded", ap(100, 0, 0.5, 0.5), []float64{0, 10, 5, 12, -4},
+			-5, 15, []int{1}},
+		{"drifts", ap(4, 0, 0, 0.5), []float64{0, 1, 2, 3, 4, 5},
+			2, 5, []int{1, 2, 3, 4, 5}},
+		{"outlier", ap(5, 0, 0, 0.5), []float64{0, 0, 0, 0, 100},
+			0, 100, []int{4}},
+		{"quantile", ap(5, 0.25, 0, 0.5), []float64{0, 0, 0, 0, 100},
+			0, 0, nil},
+		{"shrinks", ap(4, 0, 0, 0.5), []float64{0, 100, 50, 50, 50, 50},
+			50, 50, []int{1, 5}},
+		{"holds", ap(4, 0, 0, 0), []float64{0, 100, 50, 50, 50, 50},
+			0, 100, []int{1}},
+	} {
+		a := enc.NewAdaptive(c.p)
+		var shifts []enc.Shift
+		a.Notify(func(s enc.Shift) { shifts = append(shifts, s) })
+
+		for _, v := range c.stream {
+			out, b := a.Encode(v)
+			if n := len(vec.ToInt(out)); n != c.p.Active || b < 0 || b >= c.p.Buckets {
+				t.Fatalf("%s: %v in bucket %d with %d active bits", c.name, v, b, n)
+			}
+		}
+
+		if got := a.Scalar.P; got.Min != c.min || got.Max != c.max {
+			t.Errorf("%s: range [%v : %v], want [%v : %v]", c.name, got.Min, got.Max, c.min, c.max)
+		}
+		if len(shifts) != len(c.shifts) {
+			t.Errorf("%s: %d shifts, want %d", c.name, len(shifts), len(c.shifts))
+			continue
+		}
+		for i, s := range shifts {
+			if s.Iteration != c.shifts[i] {
+				t.Errorf("%s: shift %d at iteration %d, want %d", c.name, i, s.Iteration, c.shifts[i])
+			}
+			if i > 0 && s.Old != shifts[i-1].New {
+				t.Errorf("%s: shift %d is from %+v, want %+v", c.name, i, s.Old, shifts[i-1].New)
+			}
+		}
+		if n := len(shifts); n > 0 && shifts[n-1].New != a.Scalar.P {
+			t.Errorf("%s: last shift is to %+v, want %+v", c.name, shifts[n-1].New, a.Scalar.P)
+		}
+	}
+}
+
+func TestShiftRemap(t *testing.T) {
+	sp := func(min, max float64) enc.ScalarParams {
+		return enc.ScalarParams{Buckets: 11, Min: min, Max: max, Active: 3}
+	}
+
+	for _, c := range []struct {
+		s      enc.Shift
+		old    []int
+		remaps []int
+	}{
+		{enc.Shift{Old: sp(0, 10), New: sp(0, 10)}, []int{0, 4, 10}, []int{0, 4, 10}},
+		{enc.Shift{Old: sp(0, 10), New: sp(0, 20)}, []int{0, 4, 10}, []int{0, 2, 5}},
+		{enc.Shift{Old: sp(0, 10), New: sp(-10, 10)}, []int{0, 4, 10}, []int{5, 7, 10}},
+		{enc.Shift{Old: sp(0, 20), New: sp(5, 15)}, []int{0, 5, 10}, []int{0, 5, 10}},
+	} {
+		for i, b := range c.old {
+			if r := c.s.Remap(b); r != c.remaps[i] {
+				t.Errorf("%+v -> %+v: bucket %d remapped to %d, want %d",
+					c.s.Old, c.s.New, b, r, c.remaps[i])
+			}
+		}
+	}
+
+	// a drifting stream keeps values in the remapped buckets
+	p := enc.NewAdaptiveParams()
+	p.Window = 50
+	a := enc.NewAdaptive(p)
+	var prev map[float64]int
+	var shifts int
+	a.Notify(func(s enc.Shift) {
+		shifts++
+		for v, b := range prev {
+			if r, want := s.Remap(b), enc.NewScalar(s.New).Bucket(v); r < want-1 || r > want+1 {
+				t.Fatalf("%v moved from bucket %d to %d, remapped to %d", v, b, want, r)
+			}
+		}
+	})
+	for i := 0; i < 500; i++ {
+		v := float64(i) + 10*math.Sin(float64(i)/5)
+		a.Encode(v)
+		prev = make(map[float64]int)
+		for _, w := range []float64{v - 5, v, v + 5} {
+			if w >= a.Scalar.P.Min && w <= a.Scalar.P.Max {
+				prev[w] = a.Scalar.Bucket(w)
+			}
+		}
+	}
+	if shifts < 10 {
+		t.Fatalf("%d shifts over a drifting stream", shifts)
+	}
+}
+
+func TestAdaptiveValidates(t *testing.T) {
+	for _, fn := range []func(p *enc.AdaptiveParams){
+		func(p *enc.AdaptiveParams) { p.Window = 0 },
+		func(p *enc.AdaptiveParams) { p.Window = -1 },
+		func(p *enc.AdaptiveParams) { p.Buckets = 0 },
+		func(p *enc.AdaptiveParams) { p.Quantile = -0.1 },
+		func(p *enc.AdaptiveParams) { p.Quantile = 0.5 },
+	} {
+		p := enc.NewAdaptiveParams()
+		fn(&p)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%+v did not panic", p)
+				}
+			}()
+			enc.NewAdaptive(p)
+		}()
+	}
+}
+
+func TestDelta(t *testing.T) {
+	sc := enc.ScalarParams{Buckets: 21, Min: -10, Max: 10, Active: 3}
+	zero, _ := enc.NewScalar(sc).Encode(0.0)
+
+	for _, abs := range []enc.Encoder{nil, enc.NewScalar(enc.NewScalarParams())} {
+		d := enc.NewDelta(enc.NewScalar(sc), abs)
+
+		for i, s := range []struct {
+			reset  bool
+			v      float64
+			bucket int // of the delta
+			decode float64
+		}{
+			{false, 5, 10, 5},   // first value, zero delta
+			{false, 7, 12, 9},   // +2
+			{false, 4, 7, 1},    // -3
+			{true, 9, 10, 9},    // first value after reset, zero delta
+			{false, 30, 20, 40}, // +21, clamped to +10
+		} {
+			if s.reset {
+				enc.Reset(d)
+			}
+			out, b := d.Encode(s.v)
+			if b != s.bucket {
+				t.Errorf("abs=%v: %v encoded delta bucket %d, want %d", abs != nil, s.v, b, s.bucket)
+			}
+			if got := d.Decode(out).(float64); got != s.decode {
+				t.Errorf("abs=%v: %v decoded to %v, want %v", abs != nil, s.v, got, s.decode)
+			}
+			if s.bucket == 10 && !vec.Equal(vec.ToInt(out[:len(zero)]), vec.ToInt(zero)) {
+				t.Errorf("abs=%v: step %d did not encode a zero delta", abs != nil, i)
+			}
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	sc := enc.NewScalarParams()
+	sc.Min, sc.Max = -10, 10
+	d := enc.NewDelta(enc.NewScalar(sc), enc.NewRDScalar(1024, 40, 0, 1))
+	d.Encode(1.0)
+	n := len(d.Absolute.(*enc.RDScalar).Series)
+
+	c := enc.Copy(d)
+	c.Encode(5.0)
+	c.Encode(9.0)
+
+	// the original still encodes relative to its own previous value
+	want, _ := enc.NewDelta(enc.NewScalar(sc), nil).Encode(0.0)
+	got, _ := d.Encode(1.0)
+	if !vec.Equal(vec.ToInt(got[:len(want)]), vec.ToInt(want)) {
+		t.Fatal("copy shares previous value with original")
+	}
+	if got := len(d.Absolute.(*enc.RDScalar).Series); got != n {
+		t.Fatalf("copy grew the original's mapping from %d to %d", n, got)
+	}
+}
@@ -22,3 +22,36 @@ type Encoder interface {
 	Encode(interface{}) ([]bool, int)
 	Decode([]bool) interface{}
 }
+
+// Resetter is implemented by encoders whose output depends on the
+// values encoded before, such as Delta. Reset marks the start of a
+// new sequence, so the next value is encoded without reference to the
+// previous one. Learned mappings, like the range of an Adaptive, are
+// not sequence state and are kept.
+type Resetter interface {
+	Reset()
+}
+
+// Reset resets e if it implements Resetter, and otherwise does nothing.
+func Reset(e Encoder) {
+	if r, ok := e.(Resetter); ok {
+		r.Reset()
+	}
+}
+
+// Copier is implemented by encoders with state that changes as values
+// are encoded, such as the mapping of an RDScalar or the previous value
+// of a Delta. Copy returns a deep copy sharing no such state with the
+// original, so the two can be used independently.
+type Copier interface {
+	Copy() Encoder
+}
+
+// Copy returns a copy of e if it implements Copier. Any other encoder
+// does not change as it encodes, and is returned as is.
+func Copy(e Encoder) Encoder {
+	if c, ok := e.(Copier); ok {
+		return c.Copy()
+	}
+	return e
+}
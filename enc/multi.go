@@ -0,0 +1,154 @@
+package enc
+
+import "fmt"
+
+// MultiField is a single named field of a MultiEncoder. Encoder is an
+// interface, so it is not serialized; see MultiEncoder.
+type MultiField struct {
+	Name    string  `json:"name"`
+	Encoder Encoder `json:"-"`
+	Width   int     `json:"width"`
+	Offset  int     `json:"offset"`
+}
+
+// MultiEncoder encodes records of several fields, by concatenating the
+// output of a named sub-encoder for each field into a single vector.
+//
+// Records are passed to Encode as a map[string]interface{} keyed by
+// field name. Encode returns the bucket index of the Target field, so
+// that a classifier can be trained to predict that field.
+//
+// Only the layout of the fields is serialized, not their sub-encoders.
+// After unmarshalling a MultiEncoder, restore the sub-encoder of each
+// field with SetEncoder before using it.
+type MultiEncoder struct {
+	Fields []MultiField `json:"fields"`
+	Target string       `json:"target"`
+}
+
+// NewMultiEncoder returns an empty MultiEncoder. Fields are added with
+// Add, in the order they should appear in the output.
+func NewMultiEncoder() *MultiEncoder {
+	return &MultiEncoder{
+		Fields: make([]MultiField, 0),
+	}
+}
+
+// Add appends a field encoded by e, whose output is width bits wide.
+// The first field added becomes the Target.
+func (m *MultiEncoder) Add(name string, e Encoder, width int) *MultiEncoder {
+	if _, ok := m.Field(name); ok {
+		panic(fmt.Sprintf("MultiEncoder already has field %q!", name))
+	}
+	if len(m.Fields) == 0 {
+		m.Target = name
+	}
+
+	m.Fields = append(m.Fields, MultiField{
+		Name:    name,
+		Encoder: e,
+		Width:   width,
+		Offset:  m.Size(),
+	})
+	return m
+}
+
+// SetEncoder replaces the sub-encoder of the named field, which must
+// produce outputs of the field's width.
+func (m *MultiEncoder) SetEncoder(name string, e Encoder) *MultiEncoder {
+	for i := range m.Fields {
+		if m.Fields[i].Name == name {
+			m.Fields[i].Encoder = e
+			return m
+		}
+	}
+	panic(fmt.Sprintf("MultiEncoder has no field %q!", name))
+}
+
+// Field returns the named field, and whether it exists.
+func (m *MultiEncoder) Field(name string) (MultiField, bool) {
+	for i := range m.Fields {
+		if m.Fields[i].Name == name {
+			return m.Fields[i], true
+		}
+	}
+	return MultiField{}, false
+}
+
+// Size returns the total number of bits in an encoding.
+func (m *MultiEncoder) Size() int {
+	var n int
+	for i := range m.Fields {
+		n += m.Fields[i].Width
+	}
+	return n
+}
+
+// Encode encodes a record to a bit vector, and returns the bucket
+// index of the Target field.
+func (m *MultiEncoder) Encode(d interface{}) ([]bool, int) {
+	rec, ok := d.(map[string]interface{})
+	if !ok {
+		panic("MultiEncoder did not receive map[string]interface{} input value!")
+	}
+
+	out, buckets := m.EncodeFields(rec)
+	return out, buckets[m.Target]
+}
+
+// EncodeFields encodes a record to a bit vector, and returns the bucket
+// index of every field by name.
+func (m *MultiEncoder) EncodeFields(rec map[string]interface{}) ([]bool, map[string]int) {
+	out := make([]bool, m.Size())
+	buckets := make(map[string]int, len(m.Fields))
+
+	for _, f := range m.Fields {
+		v, ok := rec[f.Name]
+		if !ok {
+			panic(fmt.Sprintf("MultiEncoder record is missing field %q!", f.Name))
+		}
+		if f.Encoder == nil {
+			panic(fmt.Sprintf("MultiEncoder field %q has no encoder!", f.Name))
+		}
+
+		sv, b := f.Encoder.Encode(v)
+		if len(sv) != f.Width {
+			panic(fmt.Sprintf("MultiEncoder field %q is %d bits, expected %d!",
+				f.Name, len(sv), f.Width))
+		}
+		copy(out[f.Offset:f.Offset+f.Width], sv)
+		buckets[f.Name] = b
+	}
+
+	return out, buckets
+}
+
+// Copy returns a deep copy of m, copying every field's sub-encoder.
+func (m *MultiEncoder) Copy() Encoder {
+	c := &MultiEncoder{
+		Fields: make([]MultiField, len(m.Fields)),
+		Target: m.Target,
+	}
+	for i, f := range m.Fields {
+		f.Encoder = Copy(f.Encoder)
+		c.Fields[i] = f
+	}
+	return c
+}
+
+// Reset resets every field's sub-encoder that implements Resetter.
+func (m *MultiEncoder) Reset() {
+	for _, f := range m.Fields {
+		Reset(f.Encoder)
+	}
+}
+
+// Decode decodes each field of s with its sub-encoder, and returns the
+// results as a map[string]interface{} keyed by field name.
+func (m *MultiEncoder) Decode(s []bool) interface{} {
+	rec := make(map[string]interface{}, len(m.Fields))
+	for _, f := range m.Fields {
+		rec[f.Name] = f.Encoder.Decode(s[f.Offset : f.Offset+f.Width])
+	}
+	return rec
+}
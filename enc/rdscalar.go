@@ -7,7 +7,7 @@ import (
 	"github.com/nytopop/gohtm/vec"
 )
 
-// TODO : cleanup + Decode()
+// TODO : cleanup
 
 // RDScalar implements a random distributed scalar encoder.
 type RDScalar struct {
@@ -29,6 +29,14 @@ func NewRDScalar(n uint32, w, o int, r float64) *RDScalar {
 	}
 }
 
+// Copy returns a deep copy of r.
+func (r *RDScalar) Copy() Encoder {
+	c := *r
+	c.Series = make([]uint32, len(r.Series))
+	copy(c.Series, r.Series)
+	return &c
+}
+
 // Encode encodes a float value to a bit vector.
 func (r *RDScalar) Encode(s interface{}) ([]bool, int) {
 	// ensure we get a float64
@@ -129,6 +137,20 @@ func (r *RDScalar) Buckets() int {
 	return len(r.Series) - r.W + 1
 }
 
+// Decode returns the float64 value of the bucket that best overlaps
+// with s. Only buckets that have been encoded can be decoded.
 func (r *RDScalar) Decode(s []bool) interface{} {
-	return 0
+	var best, max int
+	for b := 0; b < r.Buckets(); b++ {
+		var overlap int
+		for _, idx := range r.Series[b : b+r.W] {
+			if s[idx] {
+				overlap++
+			}
+		}
+		if overlap > max {
+			best, max = b, overlap
+		}
+	}
+	return float64(best) * r.R
 }
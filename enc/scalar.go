@@ -1,13 +1,14 @@
 package enc
 
-// TODO : does not implement Encoder
+import "math"
 
 // ScalarParams represents a parameter set for a Scalar Encoder.
 type ScalarParams struct {
-	Buckets  int
-	Min, Max int
-	Active   int
-	Wrap     bool
+	Buckets int     `json:"buckets"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Active  int     `json:"active"`
+	Wrap    bool    `json:"wrap"`
 }
 
 // NewScalarParams returns a default param set.
@@ -21,45 +22,98 @@ func NewScalarParams() ScalarParams {
 	}
 }
 
-// Scalar is a linearly derived scalar encoder.
+// Scalar is a linearly derived scalar encoder. Values are clamped to
+// [Min : Max], and split into Buckets evenly spaced buckets. Each
+// bucket is Active contiguous bits, offset by one bit from the last.
+//
+// With Wrap set, the range is periodic; Max wraps around to Min, and
+// the active bits of the highest buckets wrap around to the start.
+//
+// Encode accepts int or float64 values, and returns the bucket index
+// along with the encoding. Decode returns a float64.
 type Scalar struct {
-	P     ScalarParams
-	Bits  int
-	Range int
+	P     ScalarParams `json:"params"`
+	Bits  int          `json:"bits"`
+	Range float64      `json:"range"`
 }
 
 // NewScalar returns a Scalar encoder initialiazed with the provided
 // ScalarParams.
 func NewScalar(p ScalarParams) *Scalar {
+	bits := p.Buckets + p.Active - 1
+	if p.Wrap {
+		bits = p.Buckets
+	}
+
 	return &Scalar{
 		P:     p,
-		Bits:  p.Buckets + p.Active - 1,
-		Range: p.Max - p.Min, // should be Absolute value
+		Bits:  bits,
+		Range: math.Abs(p.Max - p.Min),
 	}
 }
 
-// Encode asdf
-func (s *Scalar) Encode(d interface{}) []bool {
+// Bucket returns the bucket index of a value.
+func (s *Scalar) Bucket(v float64) int {
+	if s.Range == 0 {
+		return 0
+	}
+
+	var b int
+	switch s.P.Wrap {
+	case true:
+		phase := math.Mod(v-s.P.Min, s.Range) / s.Range
+		if phase < 0 {
+			phase++
+		}
+		b = int(phase*float64(s.P.Buckets)) % s.P.Buckets
+	case false:
+		v = math.Min(math.Max(v, s.P.Min), s.P.Max)
+		b = int((v-s.P.Min)/s.Range*float64(s.P.Buckets-1) + 0.5)
+	}
+	return b
+}
+
+// Encode encodes an int or float64 value to a bit vector.
+func (s *Scalar) Encode(d interface{}) ([]bool, int) {
+	var v float64
+	switch d := d.(type) {
+	case int:
+		v = float64(d)
+	case float64:
+		v = d
+	default:
+		panic("Scalar did not receive int or float64 input value!")
+	}
+
 	out := make([]bool, s.Bits)
-	i := s.P.Buckets * (d.(int) - s.P.Min) / s.Range
+	b := s.Bucket(v)
 	for j := 0; j < s.P.Active; j++ {
-		if (i + j) < (len(out) - 1) {
-			out[i+j] = true
-		} else {
-			if s.P.Wrap {
-				// TODO wraparound
-			}
-		}
+		out[(b+j)%s.Bits] = true
 	}
-	return out
+	return out, b
 }
 
-// Decode asdf
+// Decode returns the float64 value at the center of the bucket that
+// best matches sv.
 func (s *Scalar) Decode(sv []bool) interface{} {
-	for i, v := range sv {
-		if v {
-			return (i+1)*s.Range/s.P.Buckets - s.P.Min
+	var best, max int
+	for b := 0; b < s.P.Buckets; b++ {
+		var overlap int
+		for j := 0; j < s.P.Active; j++ {
+			if sv[(b+j)%s.Bits] {
+				overlap++
+			}
 		}
+		if overlap > max {
+			best, max = b, overlap
+		}
+	}
+
+	if s.P.Wrap {
+		return s.P.Min + float64(best)*s.Range/float64(s.P.Buckets)
+	}
+	if s.P.Buckets < 2 {
+		return s.P.Min
 	}
-	return 0
+	return s.P.Min + float64(best)*s.Range/float64(s.P.Buckets-1)
 }
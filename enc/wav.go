@@ -0,0 +1,82 @@
+package enc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// ReadWAV reads a PCM encoded WAV file from r, and returns its samples
+// scaled to [-1.0 : 1.0] along with the sample rate. Multi channel
+// audio is mixed down to mono. 8, 16 and 32 bit samples are supported.
+func ReadWAV(r io.Reader) ([]float32, int, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, errors.New("enc: not a RIFF WAVE file")
+	}
+
+	var channels, bits, rate int
+	var pcm []byte
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := data[pos+8:]
+		if size > len(body) {
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, errors.New("enc: short wav fmt chunk")
+			}
+			if format := binary.LittleEndian.Uint16(body[0:2]); format != 1 {
+				return nil, 0, fmt.Errorf("enc: unsupported wav format %d", format)
+			}
+			channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			rate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bits = int(binary.LittleEndian.Uint16(body[14:16]))
+		case "data":
+			pcm = body
+		}
+
+		// chunks are padded to an even size
+		pos += 8 + size + size%2
+	}
+
+	switch {
+	case channels == 0:
+		return nil, 0, errors.New("enc: missing wav fmt chunk")
+	case pcm == nil:
+		return nil, 0, errors.New("enc: missing wav data chunk")
+	case bits != 8 && bits != 16 && bits != 32:
+		return nil, 0, fmt.Errorf("enc: unsupported wav sample size %d", bits)
+	}
+
+	width := bits / 8
+	n := len(pcm) / (width * channels)
+	samples := make([]float32, n)
+	for i := range samples {
+		var sum float64
+		for c := 0; c < channels; c++ {
+			b := pcm[(i*channels+c)*width:]
+			switch bits {
+			case 8:
+				sum += (float64(b[0]) - 128) / 128
+			case 16:
+				sum += float64(int16(binary.LittleEndian.Uint16(b))) / 32768
+			case 32:
+				sum += float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648
+			}
+		}
+		samples[i] = float32(sum / float64(channels))
+	}
+
+	return samples, rate, nil
+}
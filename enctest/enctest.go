@@ -0,0 +1,265 @@
+/*
+Package enctest verifies that encoders follow the encoder design
+guidelines laid out in package enc:
+
+ 1. Semantically similar data should result in SDRs with overlapping active bits.
+ 2. The same input should always produce the same SDR as output.
+ 3. The output should have the same dimensionality for all inputs.
+ 4. The output should have similar sparsity for all inputs.
+
+Each property is checked statistically over a sample of values
+drawn from a user supplied generator, along with a check that
+decoding an encoded value lands within the encoder's resolution.
+
+It is meant to be called from tests or experiments:
+
+	err := enctest.Check(e, enctest.NewParams(enctest.Float64s(0, 100)))
+*/
+package enctest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/vec"
+)
+
+// Params contains parameters for an encoder check.
+type Params struct {
+	// Generate returns a random value to encode.
+	Generate func(rng *rand.Rand) interface{}
+
+	// Distance returns the semantic distance between two values. If
+	// nil, the semantic overlap and decode checks are skipped.
+	Distance func(a, b interface{}) float64
+
+	// Resolution is the maximum distance allowed between a value and
+	// the decoded form of its encoding.
+	Resolution float64
+
+	// SkipDecode disables the decode round trip check.
+	SkipDecode bool
+
+	// MaxSparsityDev is the maximum allowed standard deviation of the
+	// number of active bits, relative to the mean.
+	MaxSparsityDev float64
+
+	// MinActive is the minimum number of active bits required in every
+	// output, so that it can survive noise and subsampling.
+	MinActive int
+
+	Samples int
+	Seed    int64
+}
+
+// NewParams returns a default set of Params using the provided
+// value generator.
+func NewParams(gen func(rng *rand.Rand) interface{}) Params {
+	return Params{
+		Generate:       gen,
+		Distance:       Float64Distance,
+		Resolution:     1.0,
+		MaxSparsityDev: 0.1,
+		MinActive:      10,
+		Samples:        256,
+		Seed:           1,
+	}
+}
+
+// Float64s returns a generator of uniformly distributed float64 values
+// in [min, max).
+func Float64s(min, max float64) func(rng *rand.Rand) interface{} {
+	return func(rng *rand.Rand) interface{} {
+		return min + rng.Float64()*(max-min)
+	}
+}
+
+// Float64Distance returns the absolute difference between two numeric
+// values. Values that are not float64 or int are infinitely far apart.
+func Float64Distance(a, b interface{}) float64 {
+	x, okx := toFloat64(a)
+	y, oky := toFloat64(b)
+	if !okx || !oky {
+		return math.Inf(1)
+	}
+	return math.Abs(x - y)
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0.0, false
+}
+
+// Errors is returned by Check when one or more properties fail.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Check encodes a sample of generated values with e, and returns an
+// Errors describing each design guideline the encoder violates, or
+// nil if it follows all of them.
+func Check(e enc.Encoder, p Params) error {
+	rng := rand.New(rand.NewSource(p.Seed))
+
+	values := make([]interface{}, p.Samples)
+	outputs := make([][]bool, p.Samples)
+	for i := range values {
+		values[i] = p.Generate(rng)
+		outputs[i], _ = e.Encode(values[i])
+	}
+
+	var errs Errors
+	checks := []func(enc.Encoder, Params, []interface{}, [][]bool) error{
+		checkDeterminism,
+		checkWidth,
+		checkSparsity,
+		checkSemantics,
+		checkDecode,
+	}
+	for _, check := range checks {
+		if err := check(e, p, values, outputs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkDeterminism verifies guideline 2.
+func checkDeterminism(e enc.Encoder, p Params,
+	values []interface{}, outputs [][]bool) error {
+
+	for i := range values {
+		again, _ := e.Encode(values[i])
+		if !vec.Equal(vec.ToInt(again), vec.ToInt(outputs[i])) {
+			return fmt.Errorf("enctest: nondeterministic output for %v", values[i])
+		}
+	}
+	return nil
+}
+
+// checkWidth verifies guideline 3.
+func checkWidth(e enc.Encoder, p Params,
+	values []interface{}, outputs [][]bool) error {
+
+	for i := range outputs {
+		if len(outputs[i]) != len(outputs[0]) {
+			return fmt.Errorf("enctest: width %d for %v, expected %d",
+				len(outputs[i]), values[i], len(outputs[0]))
+		}
+	}
+	return nil
+}
+
+// checkSparsity verifies guideline 4.
+func checkSparsity(e enc.Encoder, p Params,
+	values []interface{}, outputs [][]bool) error {
+
+	counts := make([]float64, len(outputs))
+	var mean float64
+	for i := range outputs {
+		n := len(vec.ToInt(outputs[i]))
+		if n < p.MinActive {
+			return fmt.Errorf("enctest: %d active bits for %v, expected at least %d",
+				n, values[i], p.MinActive)
+		}
+		counts[i] = float64(n)
+		mean += counts[i]
+	}
+	mean /= float64(len(counts))
+	if mean == 0 {
+		return fmt.Errorf("enctest: no active bits in any output")
+	}
+
+	var variance float64
+	for i := range counts {
+		variance += (counts[i] - mean) * (counts[i] - mean)
+	}
+	dev := math.Sqrt(variance/float64(len(counts))) / mean
+	if dev > p.MaxSparsityDev {
+		return fmt.Errorf("enctest: active bit count deviates by %.3f, expected at most %.3f",
+			dev, p.MaxSparsityDev)
+	}
+	return nil
+}
+
+// pair is a pair of sampled values, by distance and overlap.
+type pair struct {
+	dist    float64
+	overlap int
+}
+
+type pairs []pair
+
+func (p pairs) Len() int           { return len(p) }
+func (p pairs) Less(i, j int) bool { return p[i].dist < p[j].dist }
+func (p pairs) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// checkSemantics verifies guideline 1, by comparing the mean overlap of
+// the closest quarter of sampled pairs against the furthest quarter.
+func checkSemantics(e enc.Encoder, p Params,
+	values []interface{}, outputs [][]bool) error {
+
+	if p.Distance == nil || len(values) < 8 {
+		return nil
+	}
+
+	ps := make(pairs, 0, len(values))
+	for i := 0; i+1 < len(values); i += 2 {
+		ps = append(ps, pair{
+			dist: p.Distance(values[i], values[i+1]),
+			overlap: vec.Overlap(vec.ToInt(outputs[i]),
+				vec.ToInt(outputs[i+1])),
+		})
+	}
+	sort.Sort(ps)
+
+	q := len(ps) / 4
+	var near, far float64
+	for i := 0; i < q; i++ {
+		near += float64(ps[i].overlap)
+		far += float64(ps[len(ps)-1-i].overlap)
+	}
+	if near <= far {
+		return fmt.Errorf("enctest: near values overlap by %.2f bits, far values by %.2f",
+			near/float64(q), far/float64(q))
+	}
+	return nil
+}
+
+// checkDecode verifies that decoding an output lands within
+// p.Resolution of the encoded value.
+func checkDecode(e enc.Encoder, p Params,
+	values []interface{}, outputs [][]bool) error {
+
+	if p.SkipDecode || p.Distance == nil {
+		return nil
+	}
+
+	for i := range values {
+		d := p.Distance(values[i], e.Decode(outputs[i]))
+		if d > p.Resolution {
+			return fmt.Errorf("enctest: %v decoded %.3f away, expected at most %.3f",
+				values[i], d, p.Resolution)
+		}
+	}
+	return nil
+}
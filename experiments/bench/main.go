@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nytopop/gohtm/bench"
+)
+
+func main() {
+	p := bench.NewParams()
+
+	streams := []bench.Stream{
+		bench.HighOrder(2000, 2, 6, 1),
+		bench.NoisySine(2000, 0.05, 1),
+		bench.Repeated(2000, 8, 1),
+		bench.Branching(2000, 3, 2, 3, 1),
+	}
+
+	for _, s := range streams {
+		fmt.Println(s.Name)
+		fmt.Println("iter\tprec\trecall\tanom\tsegs\tsyns\tms/iter")
+
+		reports := bench.Run(s, p)
+		for _, r := range reports {
+			fmt.Printf("%d\t%.3f\t%.3f\t%.3f\t%d\t%d\t%.2f\n",
+				r.Iteration, r.Precision, r.Recall, r.Anomaly,
+				r.Segments, r.Synapses, r.MsPerIter)
+		}
+		fmt.Printf("slowdown: %.2f %%/window\n\n", bench.Slowdown(reports)*100)
+	}
+}
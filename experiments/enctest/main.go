@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/enctest"
+)
+
+func main() {
+	p := enctest.NewParams(enctest.Float64s(0, 100))
+
+	if err := enctest.Check(enc.NewRDScalar(1024, 40, 0, 1), p); err != nil {
+		fmt.Println("rdscalar:", err)
+		return
+	}
+	fmt.Println("rdscalar: ok")
+}
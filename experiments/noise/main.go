@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/stats"
+	"github.com/nytopop/gohtm/vec"
+)
+
+/* Noise tolerance
+Encoder guideline 4 asks for enough one-bits to handle noise
+and subsampling. Verify it for the encoder by measuring overlap
+with a subsampled copy, and for the pooler by measuring output
+overlap under increasing amounts of input noise.
+*/
+
+func main() {
+	rng := rand.New(rand.NewSource(1))
+	rates := []float64{0.0, 0.1, 0.2, 0.3, 0.4, 0.5}
+
+	e := enc.NewRDScalar(1024, 40, 0, 1)
+	inputs := make([][]bool, 64)
+	for i := range inputs {
+		inputs[i], _ = e.Encode(float64(i))
+	}
+
+	// encoder: dropping active bits should still leave each input
+	// closer to itself than to any input more than a bucket away
+	fmt.Println("encoder subsampling")
+	for _, rate := range rates {
+		var fail int
+		for i := range inputs {
+			dropped := vec.ToInt(vec.Drop(inputs[i], rate, rng))
+			self := vec.Overlap(dropped, vec.ToInt(inputs[i]))
+			for j := range inputs {
+				if (j < i-1 || j > i+1) && vec.Overlap(dropped, vec.ToInt(inputs[j])) >= self {
+					fail++
+					break
+				}
+			}
+		}
+		fmt.Printf("%.1f\t%d/%d ambiguous\n", rate, fail, len(inputs))
+	}
+
+	// pooler: train, then measure output overlap under noise
+	s := sp.NewV2(sp.NewV2Params())
+	stats.Stability(s, inputs, 4)
+
+	// flipping even a few percent of all input bits adds several
+	// times as many bits as are active
+	fmt.Println("pooler noise")
+	flips := []float64{0.0, 0.005, 0.01, 0.02, 0.05}
+	robust := stats.NoiseRobustness(s, inputs, flips, 1)
+	for i := range flips {
+		fmt.Printf("%.3f\t%.3f\n", flips[i], robust[i])
+	}
+
+	// pooler: random inputs should rarely collide
+	random := make([][]bool, 64)
+	for i := range random {
+		random[i] = vec.Random(1024, 0.04, rng)
+	}
+	fmt.Printf("random pairwise overlap: %.3f\n",
+		stats.PairwiseOverlap(stats.Outputs(s, random)))
+}
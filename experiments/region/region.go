@@ -21,14 +21,13 @@ func main() {
 	var res [32768]float64
 	_, sine := vec.SineGen(32768, 1, 0.05)
 
-	//p := sp.HyperSearchV2(sine, 4)
-	//fmt.Println(p)
+	// see experiments/tuning for parameter search
 
 	for i := 0; i < len(sine); i++ {
 		fmt.Println(i)
 		fmt.Println("data:", sine[i])
 
-		result := r.Compute(sine[i], true)
+		result := r.Sense(sine[i], true)
 		res[i] = result.AnomalyScore
 
 		fmt.Println()
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/stats"
+)
+
+/* V2 vs V3
+Train both poolers on the same dataset, then compare speed,
+output sparsity, and stability of representations across epochs.
+*/
+
+func main() {
+	e := enc.NewRDScalar(1024, 40, 0, 1)
+	inputs := make([][]bool, 128)
+	for i := range inputs {
+		inputs[i], _ = e.Encode(float64(i))
+	}
+
+	poolers := []struct {
+		name string
+		s    sp.SpatialPooler
+	}{
+		{"v2", sp.NewV2(sp.NewV2Params())},
+		{"v3", sp.NewV3(sp.NewV3Params())},
+	}
+
+	for _, p := range poolers {
+		start := time.Now()
+		stability := stats.Stability(p.s, inputs, 8)
+		elapsed := time.Since(start)
+
+		r := stats.Analyze(p.s, inputs)
+		fmt.Printf("%s: %.2f ms/iter, sparsity %.4f, entropy %.3f, dead %d\n",
+			p.name, elapsed.Seconds()*1000/float64(8*len(inputs)*2),
+			r.Sparsity, r.Entropy, r.DeadColumns)
+		fmt.Printf("%s: stability %.3f\n", p.name, stability)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nytopop/gohtm/tuning"
+	"github.com/nytopop/gohtm/vec"
+)
+
+func main() {
+	_, sine := vec.SineGen(512, 1, 0.05)
+
+	p := tuning.NewParams()
+	p.SP.NumColumns = 1024
+	p.TM.NumColumns = 1024
+	p.TM.CellsPerCol = 8
+	p.Samples = 8
+	p.Space.TM = []tuning.Param{
+		{Name: "activethreshold", Min: 12, Max: 20},
+	}
+
+	results := tuning.Run(sine, p)
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Println(r.Err)
+			continue
+		}
+		fmt.Printf("score %.3f entropy %.3f overlap %.3f error %.3f\n",
+			r.Score, r.Entropy, r.PairwiseOverlap, r.Error)
+	}
+
+	if len(results) == 0 || results[0].Err != nil {
+		fmt.Println("no valid candidates")
+		return
+	}
+	spec, _ := json.MarshalIndent(results[0].Spec(p), "", "\t")
+	fmt.Printf("%s\n", spec)
+}
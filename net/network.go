@@ -16,6 +16,7 @@ import (
 type Network interface {
 	Compute(data [][]bool) [][]bool
 	Serialize() []byte
+	Reset()
 }
 
 func sizeLayer(w int) int {
@@ -87,10 +88,24 @@ func NewUnary(w, cols int) Network {
 	}
 }
 
+// Add appends a region to the top of the network.
+func (u *Unary) Add(r region.Region) *Unary {
+	u.grph = append(u.grph, r)
+	return u
+}
+
 func (u *Unary) Compute(data [][]bool) [][]bool {
 	return make([][]bool, 0)
 }
 
+// Reset marks the start of a new sequence in every region of the
+// network.
+func (u *Unary) Reset() {
+	for _, r := range u.grph {
+		r.Reset()
+	}
+}
+
 func (u *Unary) Serialize() []byte {
 	return make([]byte, 256)
 }
@@ -0,0 +1,67 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/region"
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/vec"
+)
+
+func TestUnaryReset(t *testing.T) {
+	bp := region.NewBotParams()
+	bp.SP.NumColumns = 1024
+	bp.SP.Boosting = sp.BoostNone
+	bp.TM.CellsPerCol = 4
+	bp.TM.ActiveThreshold = 8
+	bp.TM.MatchThreshold = 5
+	b := region.NewBot(enc.NewCategory(enc.NewCategoryParams()), bp)
+
+	mp := region.NewMidParams()
+	mp.SP.NumInputs = bp.SP.NumColumns * bp.TM.CellsPerCol
+	mp.SP.NumColumns = 512
+	mp.TM.CellsPerCol = 4
+	mp.TScope = 1
+	m := region.NewMid(mp)
+
+	u := (&Unary{}).Add(b).Add(m)
+	var n Network = u
+
+	for i := 0; i < 32; i++ {
+		b.Sense(i%4, nil, true)
+		m.Compute(b.BottomUp(), nil, true)
+	}
+	switch {
+	case len(vec.ToInt(b.TopDown())) == 0:
+		t.Fatal("bot predicts nothing after training")
+	case len(vec.ToInt(m.BottomUp())) == 0:
+		t.Fatal("mid has no active cells after training")
+	}
+
+	n.Reset()
+	for name, r := range map[string]region.Region{"bot": b, "mid": m} {
+		switch {
+		case len(vec.ToInt(r.BottomUp())) != 0:
+			t.Errorf("%s has active cells after reset", name)
+		case len(vec.ToInt(r.TopDown())) != 0:
+			t.Errorf("%s predicts after reset", name)
+		}
+	}
+
+	// the first input after a reset starts a new sequence, so every
+	// active column bursts instead of continuing the old one
+	b.Sense(1, nil, false)
+	active := b.BottomUp()
+	for col := 0; col < bp.SP.NumColumns; col++ {
+		var n int
+		for c := col * bp.TM.CellsPerCol; c < (col+1)*bp.TM.CellsPerCol; c++ {
+			if active[c] {
+				n++
+			}
+		}
+		if n != 0 && n != bp.TM.CellsPerCol {
+			t.Fatalf("column %d has %d of %d cells active after reset", col, n, bp.TM.CellsPerCol)
+		}
+	}
+}
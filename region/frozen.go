@@ -0,0 +1,233 @@
+package region
+
+import (
+	"github.com/nytopop/gohtm/anomaly"
+	"github.com/nytopop/gohtm/cla"
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/tm"
+)
+
+// FrozenV1 is the immutable, inference only form of a trained V1
+// Region, made with Freeze. It can be shared between goroutines, each
+// of which runs its own stream through a V1Session.
+//
+// Encoders may extend their mapping as they see new values, and some
+// keep sequence state, so each V1Session encodes with its own copy of
+// the encoder as it was when frozen. Values the encoder has not seen
+// extend that session's copy only.
+type FrozenV1 struct {
+	P V1Params
+	e enc.Encoder
+	s *sp.Frozen
+	t *tm.Frozen
+}
+
+// Freeze returns an immutable inference form of r. Later learning on
+// r does not affect the returned FrozenV1, nor does inference on the
+// FrozenV1 affect r.
+func (r *V1) Freeze() *FrozenV1 {
+	return &FrozenV1{
+		P: r.P,
+		e: enc.Copy(r.e),
+		s: freezeSP(r.s),
+		t: freezeTM(r.t),
+	}
+}
+
+// freezeSP returns the frozen form of s, or panics if it has none.
+func freezeSP(s sp.SpatialPooler) *sp.Frozen {
+	f, ok := s.(interface {
+		Freeze() *sp.Frozen
+	})
+	if !ok {
+		panic("region: cannot freeze spatial pooler")
+	}
+	return f.Freeze()
+}
+
+// freezeTM returns the frozen form of t, or panics if it has none.
+func freezeTM(t tm.TemporalMemory) *tm.Frozen {
+	f, ok := t.(interface {
+		Freeze() *tm.Frozen
+	})
+	if !ok {
+		panic("region: cannot freeze temporal memory")
+	}
+	return f.Freeze()
+}
+
+// NewSession returns a V1Session for running a stream through f.
+func (f *FrozenV1) NewSession() *V1Session {
+	return &V1Session{
+		f: f,
+		e: enc.Copy(f.e),
+		t: f.t.NewSession(),
+		l: anomaly.NewLikelihood(anomaly.NewLikelihoodParams()),
+	}
+}
+
+// V1Session is the sequence state of one stream run through a
+// FrozenV1. A V1Session must not be shared between goroutines.
+type V1Session struct {
+	f *FrozenV1
+	e enc.Encoder
+	t *tm.Session
+	l *anomaly.Likelihood
+}
+
+// Reset clears sequence state, so the next datapoint is not treated as
+// a continuation of the current sequence, resetting the temporal
+// memory and encoder.
+func (s *V1Session) Reset() {
+	s.t.Reset()
+	enc.Reset(s.e)
+}
+
+// SenseRecord senses a Record, resetting first if it is marked as the
+// start of a new sequence. The Record's Value must be a float64.
+func (s *V1Session) SenseRecord(rec Record) V1Result {
+	if rec.Reset {
+		s.Reset()
+	}
+	v, ok := rec.Value.(float64)
+	if !ok {
+		panic("region: record value is not a float64")
+	}
+	return s.Sense(v)
+}
+
+// Sense runs a datapoint through the frozen region. The classifier
+// is not yet able to infer, so Prediction is always empty.
+func (s *V1Session) Sense(datapoint float64) V1Result {
+	inputvector, _ := s.e.Encode(datapoint)
+
+	s.t.Compute(s.f.s.Compute(inputvector, false), false)
+	score := s.t.GetAnomalyScore()
+	likelihood := s.l.Compute(score)
+
+	return V1Result{
+		Datapoint:         datapoint,
+		AnomalyScore:      score,
+		AnomalyLikelihood: likelihood,
+		LogLikelihood:     anomaly.LogLikelihood(likelihood),
+		Prediction:        cla.Result{},
+	}
+}
+
+// frozenLayer is the immutable form of a layer.
+type frozenLayer struct {
+	s           *sp.Frozen
+	t           *tm.Frozen
+	numCols     int
+	cellsPerCol int
+	tScope      int
+	numCells    int
+}
+
+// freeze returns an immutable inference form of l.
+func (l *layer) freeze() frozenLayer {
+	return frozenLayer{
+		s:           freezeSP(l.inputPooler),
+		t:           freezeTM(l.memory),
+		numCols:     l.numCols,
+		cellsPerCol: l.cellsPerCol,
+		tScope:      l.tScope,
+		numCells:    len(l.active),
+	}
+}
+
+// newSession returns a layer that runs inference on f, with its own
+// sequence state.
+func (f frozenLayer) newSession() layer {
+	return layer{
+		inputPooler: f.s,
+		memory:      f.t.NewSession(),
+		numCols:     f.numCols,
+		cellsPerCol: f.cellsPerCol,
+		tScope:      f.tScope,
+		active:      make([]bool, f.numCells),
+	}
+}
+
+// FrozenBot is the immutable, inference only form of a trained Bot,
+// made with Freeze. Like FrozenV1, it can be shared between goroutines,
+// each of which runs its own stream through a session from NewSession.
+type FrozenBot struct {
+	P BotParams
+	e enc.Encoder
+	l frozenLayer
+}
+
+// Freeze returns an immutable inference form of b. Later learning on
+// b does not affect the returned FrozenBot.
+func (b *Bot) Freeze() *FrozenBot {
+	return &FrozenBot{
+		P: b.P,
+		e: enc.Copy(b.inputEncoder),
+		l: b.layer.freeze(),
+	}
+}
+
+// NewSession returns a Bot that runs inference on f, with its own
+// sequence state and copy of the encoder. The learn flags of its
+// methods are ignored, and it must not be shared between goroutines.
+func (f *FrozenBot) NewSession() *Bot {
+	return &Bot{
+		P:            f.P,
+		inputEncoder: enc.Copy(f.e),
+		layer:        f.l.newSession(),
+	}
+}
+
+// FrozenMid is the immutable, inference only form of a trained Mid,
+// made with Freeze.
+type FrozenMid struct {
+	P MidParams
+	l frozenLayer
+}
+
+// Freeze returns an immutable inference form of m. Later learning on
+// m does not affect the returned FrozenMid.
+func (m *Mid) Freeze() *FrozenMid {
+	return &FrozenMid{
+		P: m.P,
+		l: m.layer.freeze(),
+	}
+}
+
+// NewSession returns a Mid that runs inference on f, with its own
+// sequence state. The learn flag of Compute is ignored, and it must
+// not be shared between goroutines.
+func (f *FrozenMid) NewSession() *Mid {
+	return &Mid{
+		P:     f.P,
+		layer: f.l.newSession(),
+	}
+}
+
+// FrozenTop is the immutable, inference only form of a trained Top,
+// made with Freeze.
+type FrozenTop struct {
+	P TopParams
+	l frozenLayer
+}
+
+// Freeze returns an immutable inference form of t. Later learning on
+// t does not affect the returned FrozenTop.
+func (t *Top) Freeze() *FrozenTop {
+	return &FrozenTop{
+		P: t.P,
+		l: t.layer.freeze(),
+	}
+}
+
+// NewSession returns a Top that runs inference on f, with its own
+// sequence state. The learn flag of Compute is ignored, and it must
+// not be shared between goroutines.
+func (f *FrozenTop) NewSession() *Top {
+	return &Top{
+		P:     f.P,
+		layer: f.l.newSession(),
+	}
+}
@@ -0,0 +1,74 @@
+package region
+
+import (
+	"testing"
+
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/vec"
+)
+
+func TestFrozenV1Encoder(t *testing.T) {
+	r := NewV1(NewV1Params())
+	for i := 0; i < 16; i++ {
+		r.Sense(float64(i%4)*0.1, true)
+	}
+	e := r.e.(*enc.RDScalar)
+	n := len(e.Series)
+
+	// unseen values extend the session's encoder, not the region's
+	s := r.Freeze().NewSession()
+	s.Sense(2.0)
+	if len(e.Series) != n {
+		t.Fatalf("frozen inference grew the region's encoder from %d to %d buckets",
+			n, len(e.Series))
+	}
+	if s.e == r.e || s.e == s.f.e {
+		t.Fatal("session shares its encoder")
+	}
+}
+
+func TestFrozenHierarchy(t *testing.T) {
+	b, m, top := hierarchy()
+	step := func(b *Bot, m *Mid, top *Top, d int, learn bool) {
+		b.Sense(d, m.TopDown(), learn)
+		m.Compute(b.BottomUp(), top.TopDown(), learn)
+		top.Compute(m.BottomUp(), nil, learn)
+	}
+	seq := []int{0, 1, 2, 3, 0, 1, 3, 2}
+	for i := 0; i < 16; i++ {
+		for _, d := range seq {
+			step(b, m, top, d, true)
+		}
+	}
+
+	fb, fm, ft := b.Freeze().NewSession(), m.Freeze().NewSession(), top.Freeze().NewSession()
+	for _, r := range []Region{b, m, top} {
+		r.Reset()
+	}
+	var predicted int
+	for i, d := range append(seq, 1, 0, 4, 2) {
+		step(b, m, top, d, false)
+		step(fb, fm, ft, d, false)
+
+		for _, c := range []struct {
+			name      string
+			want, got Region
+		}{
+			{"bot", b, fb}, {"mid", m, fm}, {"top", top, ft},
+		} {
+			switch {
+			case !vec.Equal(vec.ToInt(c.got.BottomUp()), vec.ToInt(c.want.BottomUp())):
+				t.Fatalf("step %d: %s bottom up output differs", i, c.name)
+			case !vec.Equal(vec.ToInt(c.got.TopDown()), vec.ToInt(c.want.TopDown())):
+				t.Fatalf("step %d: %s top down output differs", i, c.name)
+			}
+		}
+		if fb.Predict() != b.Predict() {
+			t.Fatalf("step %d: bot predicts %v, want %v", i, fb.Predict(), b.Predict())
+		}
+		predicted += len(vec.ToInt(b.TopDown()))
+	}
+	if predicted == 0 {
+		t.Fatal("nothing was predicted, so nothing was compared")
+	}
+}
@@ -4,8 +4,39 @@ interface for building and using gohtm regions.
 */
 package region
 
-// Region ...
+// Region is the interface shared by all regions in a hierarchy.
+//
+// Compute iterates the region with bottomUp input from the level
+// below and topDown feedback from the level above. Every region has
+// input from below, so bottomUp must not be nil; topDown is nil if
+// the region has no level above. BottomUp returns the
+// output destined for the level above, TopDown returns the output
+// destined for the level below, in that level's own output space.
+//
+// Reset marks the start of a new sequence. It clears all sequence
+// state, so nothing is predicted or learned across the boundary;
+// temporal memory cells, classifier history and encoders that depend
+// on previous values. Learned state, such as synapses, boost factors
+// and anomaly likelihood distributions, is kept.
 type Region interface {
+	Compute(bottomUp, topDown []bool, learn bool)
+	BottomUp() []bool
+	TopDown() []bool
+	Reset()
+}
+
+var (
+	_ Region = (*V1)(nil)
+	_ Region = (*Bot)(nil)
+	_ Region = (*Mid)(nil)
+	_ Region = (*Top)(nil)
+)
+
+// Record is a datapoint in a stream. If Reset is set, the region is
+// reset before the value is computed, so Value starts a new sequence.
+type Record struct {
+	Value interface{} `json:"value"`
+	Reset bool        `json:"reset"`
 }
 
 /* Sensor region
@@ -0,0 +1,17 @@
+package region
+
+// Spec is a JSON encoded region specification, in the format of
+// experiments/rspec.json. Each component names its implementation
+// with Type, and holds that implementation's params.
+type Spec struct {
+	Encoder    Component `json:"encoder"`
+	SP         Component `json:"sp"`
+	TM         Component `json:"tm"`
+	Classifier Component `json:"classifier"`
+}
+
+// Component is one algorithm of a Spec.
+type Component struct {
+	Type   string      `json:"type"`
+	Params interface{} `json:"params"`
+}
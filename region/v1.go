@@ -1,10 +1,12 @@
 package region
 
 import (
+	"github.com/nytopop/gohtm/anomaly"
 	"github.com/nytopop/gohtm/cla"
 	"github.com/nytopop/gohtm/enc"
 	"github.com/nytopop/gohtm/sp"
 	"github.com/nytopop/gohtm/tm"
+	"github.com/nytopop/gohtm/vec"
 )
 
 // V1Params contains parameters for initialization of a V1 Region.
@@ -24,6 +26,8 @@ type V1 struct {
 	s sp.SpatialPooler
 	t tm.TemporalMemory
 	c cla.Classifier
+	l *anomaly.Likelihood
+	n int // temporal memory cells
 }
 
 // BuildV1 constructs a region from a JSON encoded region specification.
@@ -46,6 +50,7 @@ func NewV1(p V1Params) *V1 {
 	s := sp.NewV2(spar)
 	t := tm.NewV1(tpar)
 	c := cla.NewV2(cpar)
+	l := anomaly.NewLikelihood(anomaly.NewLikelihoodParams())
 
 	return &V1{
 		P: p,
@@ -53,37 +58,85 @@ func NewV1(p V1Params) *V1 {
 		s: s,
 		t: t,
 		c: c,
+		l: l,
+		n: tpar.NumColumns * tpar.CellsPerCol,
 	}
 }
 
+// V1Result is returned by a V1 Region on each call to Sense.
+// AnomalyScore is the raw anomaly score, AnomalyLikelihood and
+// LogLikelihood rate how unusual recent raw scores have been.
 type V1Result struct {
-	Datapoint    float64
-	AnomalyScore float64
-	Prediction   cla.Result
+	Datapoint         float64
+	AnomalyScore      float64
+	AnomalyLikelihood float64
+	LogLikelihood     float64
+	Prediction        cla.Result
 }
 
+// Reset marks the start of a new sequence, resetting the temporal
+// memory, classifier and encoder.
 func (r *V1) Reset() {
 	r.t.Reset()
+	r.c.Reset()
+	enc.Reset(r.e)
 }
 
-func (r *V1) Compute(datapoint float64, learn bool) V1Result {
+// SenseRecord senses a Record, resetting first if it is marked as the
+// start of a new sequence. The Record's Value must be a float64.
+func (r *V1) SenseRecord(rec Record, learn bool) V1Result {
+	if rec.Reset {
+		r.Reset()
+	}
+	v, ok := rec.Value.(float64)
+	if !ok {
+		panic("region: record value is not a float64")
+	}
+	return r.Sense(v, learn)
+}
+
+// Sense encodes a datapoint and computes the region with it, then runs
+// the classifier and anomaly likelihood over the result.
+func (r *V1) Sense(datapoint float64, learn bool) V1Result {
 	// Encode to vector
 	inputvector, bidx := r.e.Encode(datapoint)
 
 	// Compute SP and TM
-	activecolumns := r.s.Compute(inputvector, learn)
-	r.t.Compute(activecolumns, learn)
-
-	//r.t.Compute(inputvector, learn)
+	r.Compute(inputvector, nil, learn)
 
 	// Get active cells and run classifier
 	activeCells := r.t.GetActiveCells()
 	prediction := r.c.Compute(activeCells, bidx, datapoint, true, true)
-	anomaly := r.t.GetAnomalyScore()
+	score := r.t.GetAnomalyScore()
+	likelihood := r.l.Compute(score)
 
 	return V1Result{
-		Datapoint:    datapoint,
-		AnomalyScore: anomaly,
-		Prediction:   prediction,
+		Datapoint:         datapoint,
+		AnomalyScore:      score,
+		AnomalyLikelihood: likelihood,
+		LogLikelihood:     anomaly.LogLikelihood(likelihood),
+		Prediction:        prediction,
+	}
+}
+
+// Compute iterates the spatial pooler and temporal memory with an
+// encoded bottomUp input. A V1 region takes no feedback, so topDown is
+// ignored.
+func (r *V1) Compute(bottomUp, topDown []bool, learn bool) {
+	switch {
+	case bottomUp == nil:
+		panic("region: missing bottom up input")
 	}
+	r.t.Compute(r.s.Compute(bottomUp, learn), learn)
+}
+
+// BottomUp returns the currently active cells.
+func (r *V1) BottomUp() []bool {
+	return vec.ToBool(r.t.GetActiveCells(), r.n)
+}
+
+// TopDown projects the currently predicted columns back into input
+// space.
+func (r *V1) TopDown() []bool {
+	return r.s.Reconstruct(r.t.GetPrediction())
 }
@@ -3,6 +3,8 @@ package region
 import (
 	"github.com/nytopop/gohtm/enc"
 	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/tm"
+	"github.com/nytopop/gohtm/vec"
 )
 
 /* V2 region
@@ -10,22 +12,250 @@ spatial abstraction
 spatial scoping
 temporal scoping
 
+Bot, Mid and Top all share the same sp + tm layer, and differ
+only in which inputs they accept and how often they compute.
 
-
+Temporal scoping (TScope) pools bottom up input over TScope
+timesteps before the layer is computed, so higher regions see
+slower changing, more abstract sequences than the ones below.
 */
 
+// BotParams contains parameters for initialization of a Bot region.
+type BotParams struct {
+	SP sp.V2Params `json:"sp"`
+	TM tm.V1Params `json:"tm"`
+}
+
+// NewBotParams returns a default set of parameters for a Bot region.
+func NewBotParams() BotParams {
+	return BotParams{
+		SP: sp.NewV2Params(),
+		TM: tm.NewV1Params(),
+	}
+}
+
+// MidParams contains parameters for initialization of a Mid region.
+type MidParams struct {
+	SP     sp.V2Params `json:"sp"`
+	TM     tm.V1Params `json:"tm"`
+	TScope int         `json:"tscope"`
+}
+
+// NewMidParams returns a default set of parameters for a Mid region,
+// sized to sit on top of a default Bot region.
+func NewMidParams() MidParams {
+	b := NewBotParams()
+	p := MidParams{
+		SP:     sp.NewV2Params(),
+		TM:     tm.NewV1Params(),
+		TScope: 2,
+	}
+	p.SP.NumInputs = b.TM.NumColumns * b.TM.CellsPerCol
+	return p
+}
+
+// TopParams contains parameters for initialization of a Top region.
+type TopParams struct {
+	SP     sp.V2Params `json:"sp"`
+	TM     tm.V1Params `json:"tm"`
+	TScope int         `json:"tscope"`
+}
+
+// NewTopParams returns a default set of parameters for a Top region,
+// sized to sit on top of a default Mid region.
+func NewTopParams() TopParams {
+	m := NewMidParams()
+	p := TopParams{
+		SP:     sp.NewV2Params(),
+		TM:     tm.NewV1Params(),
+		TScope: 2,
+	}
+	p.SP.NumInputs = m.TM.NumColumns * m.TM.CellsPerCol
+	return p
+}
+
+// layer is the sp + tm core shared by all V2 regions.
+type layer struct {
+	inputPooler sp.SpatialPooler
+	memory      tm.TemporalMemory
+	numCols     int
+	cellsPerCol int
+	tScope      int
+
+	step     int
+	pooled   []bool // bottom up input pooled over tScope steps
+	feedback []bool // top down input, in active cell space
+	active   []bool // active cells
+}
+
+func newLayer(sparams sp.V2Params, tparams tm.V1Params, tScope int) layer {
+	tparams.NumColumns = sparams.NumColumns
+	if tScope < 1 {
+		tScope = 1
+	}
+
+	return layer{
+		inputPooler: sp.NewV2(sparams),
+		memory:      tm.NewV1(tparams),
+		numCols:     tparams.NumColumns,
+		cellsPerCol: tparams.CellsPerCol,
+		tScope:      tScope,
+		active:      make([]bool, tparams.NumColumns*tparams.CellsPerCol),
+	}
+}
+
+// Compute pools bottomUp input, and once every tScope steps runs the
+// pooled input through the spatial pooler and temporal memory. The
+// topDown input is kept as feedback for the next call to TopDown.
+func (l *layer) Compute(bottomUp, topDown []bool, learn bool) {
+	switch {
+	case bottomUp == nil:
+		panic("region: missing bottom up input")
+	case topDown != nil && len(topDown) != len(l.active):
+		panic("region: mismatched feedback dimensions")
+	case l.pooled != nil && len(bottomUp) != len(l.pooled):
+		panic("region: mismatched input dimensions")
+	}
+	l.feedback = nil
+	if topDown != nil {
+		l.feedback = make([]bool, len(topDown))
+		copy(l.feedback, topDown)
+	}
+
+	if l.pooled == nil {
+		l.pooled = make([]bool, len(bottomUp))
+	}
+	for i := range bottomUp {
+		l.pooled[i] = l.pooled[i] || bottomUp[i]
+	}
+
+	l.step++
+	if l.step < l.tScope {
+		return
+	}
+
+	cols := l.inputPooler.Compute(l.pooled, learn)
+	l.memory.Compute(cols, learn)
+	l.active = vec.ToBool(l.memory.GetActiveCells(), len(l.active))
+
+	l.step = 0
+	l.pooled = make([]bool, len(l.pooled))
+}
+
+// BottomUp returns the currently active cells.
+func (l *layer) BottomUp() []bool {
+	return l.active
+}
+
+// TopDown projects the currently predicted columns back into input
+// space. If feedback from a higher region agrees with at least one
+// predicted column, the prediction is narrowed to those columns.
+func (l *layer) TopDown() []bool {
+	pred := l.memory.GetPrediction()
+
+	if l.feedback != nil {
+		narrow := make([]bool, l.numCols)
+		var agree bool
+		for i := range l.feedback {
+			col := i / l.cellsPerCol
+			if l.feedback[i] && pred[col] {
+				narrow[col] = true
+				agree = true
+			}
+		}
+		if agree {
+			pred = narrow
+		}
+	}
+
+	return l.inputPooler.Reconstruct(pred)
+}
+
+// Reset clears temporal state so sequences are not learned between
+// the current and next time step.
+func (l *layer) Reset() {
+	l.memory.Reset()
+	l.step = 0
+	l.pooled = nil
+	l.feedback = nil
+	l.active = make([]bool, len(l.active))
+}
+
 // Bot. no scoping, bUpDown
 type Bot struct {
-	inputEncoder enc.Encoder      // all have this
-	inputPooler  sp.SpatialPooler // all have this
+	P            BotParams
+	inputEncoder enc.Encoder // all have this
+	layer
+}
+
+// NewBot returns a new Bot region that encodes datapoints with e.
+func NewBot(e enc.Encoder, p BotParams) *Bot {
+	return &Bot{
+		P:            p,
+		inputEncoder: e,
+		layer:        newLayer(p.SP, p.TM, 1),
+	}
+}
 
-	// Compute(learn bool)
+// Sense encodes a datapoint and computes the region with it, returning
+// the encoder's bucket index for the datapoint.
+func (b *Bot) Sense(d interface{}, topDown []bool, learn bool) int {
+	input, bidx := b.inputEncoder.Encode(d)
+	b.Compute(input, topDown, learn)
+	return bidx
+}
+
+// Reset marks the start of a new sequence, resetting the layer and
+// the region's encoder.
+func (b *Bot) Reset() {
+	b.layer.Reset()
+	enc.Reset(b.inputEncoder)
+}
+
+// SenseRecord senses a Record, resetting first if it is marked as the
+// start of a new sequence.
+func (b *Bot) SenseRecord(rec Record, topDown []bool, learn bool) int {
+	if rec.Reset {
+		b.Reset()
+	}
+	return b.Sense(rec.Value, topDown, learn)
+}
+
+// Predict decodes the current top down output with the region's encoder.
+func (b *Bot) Predict() interface{} {
+	return b.inputEncoder.Decode(b.TopDown())
 }
 
 // Mid. ts scoping, bUpDown, tDownUp
 type Mid struct {
+	P MidParams
+	layer
+}
+
+// NewMid returns a new Mid region initialized with the provided MidParams.
+func NewMid(p MidParams) *Mid {
+	return &Mid{
+		P:     p,
+		layer: newLayer(p.SP, p.TM, p.TScope),
+	}
 }
 
 // Top. ts scoping, tDownUp
 type Top struct {
+	P TopParams
+	layer
+}
+
+// NewTop returns a new Top region initialized with the provided TopParams.
+func NewTop(p TopParams) *Top {
+	return &Top{
+		P:     p,
+		layer: newLayer(p.SP, p.TM, p.TScope),
+	}
+}
+
+// Compute iterates the region with bottomUp input. There is nothing
+// above a Top region, so topDown is ignored.
+func (t *Top) Compute(bottomUp, topDown []bool, learn bool) {
+	t.layer.Compute(bottomUp, nil, learn)
 }
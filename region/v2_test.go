@@ -0,0 +1,119 @@
+package region
+
+import (
+	"testing"
+
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/vec"
+)
+
+// hierarchy returns a small Bot, Mid and Top stacked on each other.
+func hierarchy() (*Bot, *Mid, *Top) {
+	bp := NewBotParams()
+	bp.SP.NumColumns = 1024
+	bp.SP.Boosting = sp.BoostNone
+	bp.TM.CellsPerCol = 4
+	bp.TM.ActiveThreshold = 8
+	bp.TM.MatchThreshold = 5
+
+	mp := NewMidParams()
+	mp.SP.NumInputs = bp.SP.NumColumns * bp.TM.CellsPerCol
+	mp.SP.NumColumns = 512
+	mp.TM.CellsPerCol = 4
+
+	tp := NewTopParams()
+	tp.SP.NumInputs = mp.SP.NumColumns * mp.TM.CellsPerCol
+	tp.SP.NumColumns = 256
+	tp.TM.CellsPerCol = 4
+
+	b := NewBot(enc.NewCategory(enc.NewCategoryParams()), bp)
+	return b, NewMid(mp), NewTop(tp)
+}
+
+func TestHierarchyDimensions(t *testing.T) {
+	b, m, top := hierarchy()
+
+	for i := 0; i < 8; i++ {
+		b.Sense(i%4, m.TopDown(), true)
+		m.Compute(b.BottomUp(), top.TopDown(), true)
+		top.Compute(m.BottomUp(), nil, true)
+	}
+
+	switch {
+	case len(b.BottomUp()) != m.P.SP.NumInputs:
+		t.Fatalf("bot output %d bits, mid input %d", len(b.BottomUp()), m.P.SP.NumInputs)
+	case len(m.BottomUp()) != top.P.SP.NumInputs:
+		t.Fatalf("mid output %d bits, top input %d", len(m.BottomUp()), top.P.SP.NumInputs)
+	case len(m.TopDown()) != len(b.BottomUp()):
+		t.Fatalf("mid feedback %d bits, bot cells %d", len(m.TopDown()), len(b.BottomUp()))
+	case len(top.TopDown()) != len(m.BottomUp()):
+		t.Fatalf("top feedback %d bits, mid cells %d", len(top.TopDown()), len(m.BottomUp()))
+	case len(b.TopDown()) != b.P.SP.NumInputs:
+		t.Fatalf("bot top down %d bits, input %d", len(b.TopDown()), b.P.SP.NumInputs)
+	}
+}
+
+func TestBotFeedbackNarrows(t *testing.T) {
+	b, _, _ := hierarchy()
+
+	// 0 1 is followed by either 2 or 3
+	for i := 0; i < 64; i++ {
+		b.Reset()
+		for _, x := range []int{0, 1, 2 + i%2} {
+			b.Sense(x, nil, true)
+		}
+	}
+
+	b.Reset()
+	b.Sense(0, nil, false)
+	b.Sense(1, nil, false)
+	pred := vec.ToInt(b.memory.GetPrediction())
+	full := vec.ToInt(b.TopDown())
+	if len(pred) < 2 {
+		t.Fatalf("%d predicted columns after training", len(pred))
+	}
+
+	// feedback agreeing with half of the predicted columns
+	feedback := make([]bool, len(b.active))
+	for _, col := range pred[:len(pred)/2] {
+		for j := 0; j < b.cellsPerCol; j++ {
+			feedback[col*b.cellsPerCol+j] = true
+		}
+	}
+
+	b.Reset()
+	b.Sense(0, nil, false)
+	b.Sense(1, feedback, false)
+	narrow := vec.ToInt(b.TopDown())
+	if len(narrow) == 0 || len(narrow) >= len(full) {
+		t.Fatalf("feedback gave %d top down bits, %d without", len(narrow), len(full))
+	}
+	if vec.Overlap(narrow, full) != len(narrow) {
+		t.Fatal("narrowed top down output is not a subset of the full output")
+	}
+
+	// the layer keeps its own copy of the feedback
+	for i := range feedback {
+		feedback[i] = false
+	}
+	if got := vec.ToInt(b.TopDown()); !vec.Equal(got, narrow) {
+		t.Fatal("changing the caller's feedback changed the layer's state")
+	}
+}
+
+func TestV1Region(t *testing.T) {
+	r := NewV1(NewV1Params())
+	for i := 0; i < 8; i++ {
+		r.Sense(float64(i%4)*0.1, true)
+	}
+
+	input, _ := r.e.Encode(0.1)
+	r.Compute(input, nil, false)
+	switch {
+	case len(r.BottomUp()) != r.n:
+		t.Fatalf("bottom up %d bits, want %d", len(r.BottomUp()), r.n)
+	case len(r.TopDown()) != len(input):
+		t.Fatalf("top down %d bits, want %d", len(r.TopDown()), len(input))
+	}
+}
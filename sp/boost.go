@@ -0,0 +1,50 @@
+package sp
+
+import "math"
+
+// Boosting selects how boost factors are derived from a cell's active
+// duty cycle. The zero value is BoostLinear.
+type Boosting string
+
+// Boosting strategies.
+const (
+	// BoostLinear boosts cells active less often than the target
+	// sparsity by up to MaxBoost, falling linearly to 1.0 as their
+	// duty cycle approaches the target.
+	BoostLinear Boosting = "linear"
+
+	// BoostExponential boosts every cell by
+	// exp(-BoostStrength * (dutyCycle - sparsity)), so cells are
+	// boosted above 1.0 when under the target and below 1.0 when over.
+	BoostExponential Boosting = "exponential"
+
+	// BoostNone disables boosting.
+	BoostNone Boosting = "none"
+)
+
+// validate panics if b is not a known boosting strategy.
+func (b Boosting) validate() {
+	switch b {
+	case BoostLinear, BoostExponential, BoostNone, "":
+	default:
+		panic("sp: unknown boosting strategy")
+	}
+}
+
+// boostFactor returns the boost factor for a cell with the provided
+// active duty cycle.
+func boostFactor(b Boosting, duty, sparsity, maxBoost, strength float64) float64 {
+	switch b {
+	case BoostNone:
+		return 1.0
+	case BoostExponential:
+		return math.Exp(-strength * (duty - sparsity))
+	case BoostLinear, "":
+		if duty < sparsity {
+			r := 1 - (duty / sparsity)
+			return r*maxBoost + 1
+		}
+		return 1.0
+	}
+	panic("sp: unknown boosting strategy")
+}
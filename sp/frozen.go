@@ -0,0 +1,115 @@
+package sp
+
+import "sort"
+
+// Frozen is an immutable, inference only SpatialPooler, made from a
+// trained V2 or V3 with Freeze. The learn flag of Compute is ignored;
+// there is no boosting, no duty cycle tracking and no synapse
+// adaptation, so a Frozen can be shared between goroutines.
+//
+// Only connected synapses are kept, as flat lists of input indices.
+type Frozen struct {
+	numColumns int
+	numInputs  int
+	active     int // columns active on full stimulus
+	threshold  int // stimulus threshold
+	offsets    []int32
+	inputs     []int32
+}
+
+// newFrozen returns an empty Frozen, with no connected synapses.
+func newFrozen(numColumns, numInputs int, sparsity float64,
+	threshold int) *Frozen {
+
+	return &Frozen{
+		numColumns: numColumns,
+		numInputs:  numInputs,
+		active:     int(sparsity * float64(numColumns)),
+		threshold:  threshold,
+		offsets:    make([]int32, 1, numColumns+1),
+	}
+}
+
+// Freeze returns an immutable inference form of s. Later learning on
+// s does not affect the returned Frozen.
+func (s *V2) Freeze() *Frozen {
+	f := newFrozen(s.P.NumColumns, s.P.NumInputs, s.P.Sparsity,
+		s.P.StimulusThreshold)
+	for i := range s.Cells {
+		for _, syn := range s.Cells[i].Synapses {
+			if syn.Perm >= s.P.SynPermConnected {
+				f.inputs = append(f.inputs, int32(syn.Idx))
+			}
+		}
+		f.offsets = append(f.offsets, int32(len(f.inputs)))
+	}
+	return f
+}
+
+// Freeze returns an immutable inference form of s. Later learning on
+// s does not affect the returned Frozen.
+func (s *V3) Freeze() *Frozen {
+	f := newFrozen(s.P.NumColumns, s.P.NumInputs, s.P.Sparsity,
+		s.P.StimulusThreshold)
+	for i := range s.Cells {
+		for _, syn := range s.Cells[i].Synapses {
+			if syn.Perm >= s.P.SynPermConnected {
+				f.inputs = append(f.inputs, int32(syn.Idx))
+			}
+		}
+		f.offsets = append(f.offsets, int32(len(f.inputs)))
+	}
+	return f
+}
+
+// Compute returns the active columns for input. Columns are ranked by
+// raw overlap, and those below the stimulus threshold never activate.
+func (f *Frozen) Compute(input []bool, learn bool) []bool {
+	switch {
+	case len(input) != f.numInputs:
+		panic("sp: mismatched input dimensions")
+	}
+
+	overlaps := make(V2InhNet, f.numColumns)
+	for i := range overlaps {
+		var o int
+		for _, idx := range f.inputs[f.offsets[i]:f.offsets[i+1]] {
+			if input[idx] {
+				o++
+			}
+		}
+		overlaps[i] = V2InhCell{idx: i, olap: float64(o)}
+	}
+
+	// sort cells by overlap score, descending order
+	sort.Sort(overlaps)
+
+	n := f.active
+	activeCells := make([]bool, f.numColumns)
+	for i := 0; i < len(overlaps) && n > 0; i++ {
+		if int(overlaps[i].olap) >= f.threshold {
+			activeCells[overlaps[i].idx] = true
+			n--
+		}
+	}
+	return activeCells
+}
+
+// Reconstruct returns the input bits connected to any of the provided
+// columns.
+func (f *Frozen) Reconstruct(cols []bool) []bool {
+	switch {
+	case len(cols) != f.numColumns:
+		panic("sp: mismatched column dimensions")
+	}
+
+	input := make([]bool, f.numInputs)
+	for i := range cols {
+		if cols[i] {
+			for _, idx := range f.inputs[f.offsets[i]:f.offsets[i+1]] {
+				input[idx] = true
+			}
+		}
+	}
+	return input
+}
@@ -0,0 +1,52 @@
+package sp_test
+
+import (
+	"testing"
+
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/vec"
+)
+
+func TestFrozenMatches(t *testing.T) {
+	type freezer interface {
+		sp.SpatialPooler
+		Freeze() *sp.Frozen
+	}
+
+	for name, s := range map[string]freezer{
+		"v2": sp.NewV2(sp.NewV2Params()),
+		"v3": sp.NewV3(sp.NewV3Params()),
+	} {
+		train := inputs(16)
+		for epoch := 0; epoch < 4; epoch++ {
+			for _, input := range train {
+				s.Compute(input, true)
+			}
+		}
+		f := s.Freeze()
+
+		// a fixed sequence, seen and unseen in training
+		seq := append(train, inputs(48)[16:]...)
+		frozen := make([][]bool, len(seq))
+		for i, input := range seq {
+			want, got := s.Compute(input, false), f.Compute(input, false)
+			if !vec.Equal(vec.ToInt(got), vec.ToInt(want)) {
+				t.Fatalf("%s: input %d: frozen output differs", name, i)
+			}
+			if !vec.Equal(vec.ToInt(f.Reconstruct(got)), vec.ToInt(s.Reconstruct(want))) {
+				t.Fatalf("%s: input %d: frozen reconstruction differs", name, i)
+			}
+			frozen[i] = got
+		}
+
+		// later learning does not reach the frozen form
+		for _, input := range seq {
+			s.Compute(input, true)
+		}
+		for i, input := range seq {
+			if !vec.Equal(vec.ToInt(f.Compute(input, true)), vec.ToInt(frozen[i])) {
+				t.Fatalf("%s: input %d: frozen output changed after learning", name, i)
+			}
+		}
+	}
+}
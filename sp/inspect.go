@@ -0,0 +1,168 @@
+package sp
+
+// Inspector exposes the internal state of a spatial pooler for
+// diagnostics and visualization. Every method returns a copy indexed
+// by column, so callers cannot modify the pooler through them.
+//
+// Overlaps and BoostedOverlaps describe the last call to Compute, and
+// are nil before the first. BoostedOverlaps equal Overlaps on steps
+// where learning was disabled, as boosting is only applied in learning.
+type Inspector interface {
+	Overlaps() []int
+	BoostedOverlaps() []float64
+	BoostFactors() []float64
+	OverlapDutyCycles() []float64
+	ActiveDutyCycles() []float64
+	ConnectedCounts() []int
+	Permanences() [][]float32
+}
+
+// Overlaps returns the raw overlap of each column from the last step.
+func (s *V2) Overlaps() []int {
+	return copyInts(s.overlaps)
+}
+
+// BoostedOverlaps returns the boosted overlap of each column from the
+// last step.
+func (s *V2) BoostedOverlaps() []float64 {
+	return copyFloats(s.boosted)
+}
+
+// BoostFactors returns the boost factor of each column.
+func (s *V2) BoostFactors() []float64 {
+	out := make([]float64, len(s.Cells))
+	for i := range s.Cells {
+		out[i] = s.Cells[i].boostFactor
+	}
+	return out
+}
+
+// OverlapDutyCycles returns the overlap duty cycle of each column.
+func (s *V2) OverlapDutyCycles() []float64 {
+	out := make([]float64, len(s.Cells))
+	for i := range s.Cells {
+		out[i] = s.Cells[i].overlapDutyCycle
+	}
+	return out
+}
+
+// ActiveDutyCycles returns the active duty cycle of each column.
+func (s *V2) ActiveDutyCycles() []float64 {
+	out := make([]float64, len(s.Cells))
+	for i := range s.Cells {
+		out[i] = s.Cells[i].activeDutyCycle
+	}
+	return out
+}
+
+// ConnectedCounts returns the number of connected synapses on each
+// column.
+func (s *V2) ConnectedCounts() []int {
+	out := make([]int, len(s.Cells))
+	for i := range s.Cells {
+		for _, syn := range s.Cells[i].Synapses {
+			if syn.Perm >= s.P.SynPermConnected {
+				out[i]++
+			}
+		}
+	}
+	return out
+}
+
+// Permanences returns a NumColumns x NumInputs matrix of synapse
+// permanences. Inputs outside a column's potential pool are 0.
+func (s *V2) Permanences() [][]float32 {
+	out := make([][]float32, len(s.Cells))
+	for i := range s.Cells {
+		out[i] = make([]float32, s.P.NumInputs)
+		for _, syn := range s.Cells[i].Synapses {
+			out[i][syn.Idx] = syn.Perm
+		}
+	}
+	return out
+}
+
+// Overlaps returns the raw overlap of each column from the last step.
+func (s *V3) Overlaps() []int {
+	return copyInts(s.overlaps)
+}
+
+// BoostedOverlaps returns the boosted overlap of each column from the
+// last step.
+func (s *V3) BoostedOverlaps() []float64 {
+	return copyFloats(s.boosted)
+}
+
+// BoostFactors returns the boost factor of each column.
+func (s *V3) BoostFactors() []float64 {
+	out := make([]float64, len(s.Cells))
+	for i := range s.Cells {
+		out[i] = s.Cells[i].BoostFactor
+	}
+	return out
+}
+
+// OverlapDutyCycles returns the overlap duty cycle of each column.
+func (s *V3) OverlapDutyCycles() []float64 {
+	out := make([]float64, len(s.Cells))
+	for i := range s.Cells {
+		out[i] = s.Cells[i].OverlapDutyCycle
+	}
+	return out
+}
+
+// ActiveDutyCycles returns the active duty cycle of each column.
+func (s *V3) ActiveDutyCycles() []float64 {
+	out := make([]float64, len(s.Cells))
+	for i := range s.Cells {
+		out[i] = s.Cells[i].ActiveDutyCycle
+	}
+	return out
+}
+
+// ConnectedCounts returns the number of connected synapses on each
+// column.
+func (s *V3) ConnectedCounts() []int {
+	out := make([]int, len(s.Cells))
+	for i := range s.Cells {
+		for _, syn := range s.Cells[i].Synapses {
+			if syn.Perm >= s.P.SynPermConnected {
+				out[i]++
+			}
+		}
+	}
+	return out
+}
+
+// Permanences returns a NumColumns x NumInputs matrix of synapse
+// permanences. Inputs outside a column's potential pool are 0.
+func (s *V3) Permanences() [][]float32 {
+	out := make([][]float32, len(s.Cells))
+	for i := range s.Cells {
+		out[i] = make([]float32, s.P.NumInputs)
+		for _, syn := range s.Cells[i].Synapses {
+			out[i][syn.Idx] = syn.Perm
+		}
+	}
+	return out
+}
+
+// copyInts returns a copy of v, or nil if v is nil.
+func copyInts(v []int) []int {
+	if v == nil {
+		return nil
+	}
+	out := make([]int, len(v))
+	copy(out, v)
+	return out
+}
+
+// copyFloats returns a copy of v, or nil if v is nil.
+func copyFloats(v []float64) []float64 {
+	if v == nil {
+		return nil
+	}
+	out := make([]float64, len(v))
+	copy(out, v)
+	return out
+}
@@ -0,0 +1,145 @@
+package sp_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/vec"
+)
+
+// inspectable is a spatial pooler that can be inspected.
+type inspectable interface {
+	sp.SpatialPooler
+	sp.Inspector
+}
+
+// connected is the default SynPermConnected of both poolers.
+var connected = sp.NewV2Params().SynPermConnected
+
+func poolers() map[string]inspectable {
+	return map[string]inspectable{
+		"v2": sp.NewV2(sp.NewV2Params()),
+		"v3": sp.NewV3(sp.NewV3Params()),
+	}
+}
+
+// learned returns the state of s that only learning may change.
+func learned(s sp.Inspector) []interface{} {
+	return []interface{}{
+		s.BoostFactors(),
+		s.OverlapDutyCycles(),
+		s.ActiveDutyCycles(),
+		s.ConnectedCounts(),
+		s.Permanences(),
+	}
+}
+
+func TestInspectLastCompute(t *testing.T) {
+	in := inputs(8)
+	for name, s := range poolers() {
+		if s.Overlaps() != nil || s.BoostedOverlaps() != nil {
+			t.Fatalf("%s: overlaps before the first step", name)
+		}
+		for _, input := range in {
+			s.Compute(input, true)
+		}
+
+		for i, input := range in {
+			learn := i%2 == 0
+			boost, perms := s.BoostFactors(), s.Permanences()
+			active := s.Compute(input, learn)
+			overlaps, boosted := s.Overlaps(), s.BoostedOverlaps()
+
+			// overlaps are the connected synapses on active inputs, as
+			// of before the step learned
+			for c := range perms {
+				var want int
+				for j, p := range perms[c] {
+					if p >= connected && input[j] {
+						want++
+					}
+				}
+				if overlaps[c] != want {
+					t.Fatalf("%s: column %d overlap %d, want %d", name, c, overlaps[c], want)
+				}
+
+				want2 := float64(overlaps[c])
+				if learn {
+					want2 *= boost[c]
+				}
+				if boosted[c] != want2 {
+					t.Fatalf("%s: learn=%v: column %d boosted overlap %.3f, want %.3f",
+						name, learn, c, boosted[c], want2)
+				}
+			}
+
+			// every active column beats every inactive one
+			min := -1.0
+			for _, c := range vec.ToInt(active) {
+				if min < 0 || boosted[c] < min {
+					min = boosted[c]
+				}
+			}
+			for c := range active {
+				if !active[c] && boosted[c] > min {
+					t.Fatalf("%s: inactive column %d boosted overlap %.3f above %.3f",
+						name, c, boosted[c], min)
+				}
+			}
+		}
+	}
+}
+
+func TestInspectInference(t *testing.T) {
+	in := inputs(8)
+	for name, s := range poolers() {
+		for _, input := range in {
+			s.Compute(input, true)
+		}
+
+		before := learned(s)
+		for _, input := range inputs(16) {
+			s.Compute(input, false)
+		}
+		if !reflect.DeepEqual(before, learned(s)) {
+			t.Fatalf("%s: inference changed learned state", name)
+		}
+
+		s.Compute(in[0], true)
+		if reflect.DeepEqual(before, learned(s)) {
+			t.Fatalf("%s: learning did not change learned state", name)
+		}
+	}
+}
+
+func TestInspectCopies(t *testing.T) {
+	for name, s := range poolers() {
+		s.Compute(inputs(1)[0], true)
+
+		counts := s.ConnectedCounts()
+		for c, p := range s.Permanences() {
+			var n int
+			for _, v := range p {
+				if v >= connected {
+					n++
+				}
+			}
+			if counts[c] != n {
+				t.Fatalf("%s: column %d has %d connected synapses, want %d", name, c, counts[c], n)
+			}
+		}
+
+		before := learned(s)
+		overlaps := s.Overlaps()
+		s.Overlaps()[0] = -1
+		s.BoostedOverlaps()[0] = -1
+		s.BoostFactors()[0] = -1
+		s.ActiveDutyCycles()[0] = -1
+		s.ConnectedCounts()[0] = -1
+		s.Permanences()[0][0] = -1
+		if !reflect.DeepEqual(before, learned(s)) || !reflect.DeepEqual(overlaps, s.Overlaps()) {
+			t.Fatalf("%s: mutating an accessor's result changed the pooler", name)
+		}
+	}
+}
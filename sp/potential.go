@@ -0,0 +1,154 @@
+package sp
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Potential selects how the potential pool of each column, the inputs
+// it may grow synapses to, is chosen. The zero value is PotentialLocal.
+type Potential string
+
+// Potential pool strategies.
+const (
+	// PotentialLocal samples PotentialPct of the inputs within
+	// PotentialRadius of the column's center in input space, wrapping
+	// around at the edges. If PotentialRadius is <= 0, or covers the
+	// whole input, every input is in range exactly once.
+	PotentialLocal Potential = "local"
+
+	// PotentialUniform samples PotentialPct of all inputs, ignoring
+	// PotentialRadius.
+	PotentialUniform Potential = "uniform"
+
+	// PotentialGaussian includes each input with probability
+	// PotentialPct * exp(-d^2 / 2r^2), for d the distance from the
+	// column's center and r the PotentialRadius, which must be > 0.
+	PotentialGaussian Potential = "gaussian"
+
+	// PotentialMask samples PotentialPct of the inputs set in one of
+	// PotentialMasks. Columns are split evenly between the masks in
+	// order, so with two masks the first half of the columns sample
+	// from the first mask.
+	PotentialMask Potential = "mask"
+)
+
+// pool describes the potential pool params shared by V2 and V3.
+type pool struct {
+	kind       Potential
+	numColumns int
+	numInputs  int
+	radius     int
+	pct        float64
+	masks      [][]bool
+}
+
+// validate panics if the pool params cannot be used.
+func (p pool) validate() {
+	switch p.kind {
+	case PotentialGaussian:
+		if p.radius <= 0 {
+			panic("sp: gaussian potential pool requires PotentialRadius > 0")
+		}
+	case PotentialMask:
+		if len(p.masks) == 0 {
+			panic("sp: mask potential pool requires PotentialMasks")
+		}
+		for i := range p.masks {
+			if len(p.masks[i]) != p.numInputs {
+				panic("sp: mismatched potential mask dimensions")
+			}
+		}
+	case PotentialLocal, PotentialUniform, "":
+	default:
+		panic("sp: unknown potential pool strategy")
+	}
+}
+
+// sample returns the input indices in the potential pool of a cell.
+func (p pool) sample(cell int) []int {
+	// Find centerpoint in input space for this cell
+	ratio := float64(cell) / float64(p.numColumns)
+	center := int(float64(p.numInputs) * ratio)
+
+	var candidates []int
+	switch p.kind {
+	case PotentialUniform:
+		candidates = neighbors(center, 0, p.numInputs)
+
+	case PotentialGaussian:
+		// every input is a bernoulli trial weighted by distance
+		var idxs []int
+		for i := 0; i < p.numInputs; i++ {
+			d := float64(distance(center, i, p.numInputs))
+			r := float64(p.radius)
+			if rand.Float64() < p.pct*math.Exp(-d*d/(2*r*r)) {
+				idxs = append(idxs, i)
+			}
+		}
+		return idxs
+
+	case PotentialMask:
+		mask := p.masks[cell*len(p.masks)/p.numColumns]
+		for i := range mask {
+			if mask[i] {
+				candidates = append(candidates, i)
+			}
+		}
+
+	default:
+		candidates = neighbors(center, p.radius, p.numInputs)
+	}
+
+	// Take random sample of the candidates
+	n := int(float64(len(candidates)) * p.pct)
+	sample := rand.Perm(len(candidates))[:n]
+	idxs := make([]int, n)
+	for i := range sample {
+		idxs[i] = candidates[sample[i]]
+	}
+	return idxs
+}
+
+// neighbors returns all input indices within radius of the provided
+// input index, wrapping around at the edges. If radius is <= 0, then
+// all input indices are returned.
+func neighbors(input, radius, numInputs int) []int {
+	if radius <= 0 || radius*2+1 >= numInputs {
+		nbs := make([]int, numInputs)
+		for i := range nbs {
+			nbs[i] = i
+		}
+		return nbs
+	}
+
+	nbs := make([]int, 0, radius*2+1)
+	for i := input - radius; i <= input+radius; i++ {
+		nbs = append(nbs, (i+numInputs)%numInputs)
+	}
+	return nbs
+}
+
+// distance returns the distance between inputs a and b, wrapping
+// around at the edges.
+func distance(a, b, numInputs int) int {
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	if numInputs-d < d {
+		return numInputs - d
+	}
+	return d
+}
+
+// RangeMask returns a potential mask over numInputs inputs, with the
+// width inputs starting at offset set. Use it to give columns a
+// receptive field over one field of a multi-field encoding.
+func RangeMask(numInputs, offset, width int) []bool {
+	mask := make([]bool, numInputs)
+	for i := offset; i < offset+width && i < numInputs; i++ {
+		mask[i] = true
+	}
+	return mask
+}
@@ -0,0 +1,172 @@
+package sp
+
+import "testing"
+
+// checkPool fails if idxs has duplicates, or inputs for which in is
+// false.
+func checkPool(t *testing.T, name string, idxs []int, in func(i int) bool) {
+	seen := make(map[int]bool)
+	for _, i := range idxs {
+		switch {
+		case seen[i]:
+			t.Fatalf("%s: input %d sampled twice", name, i)
+		case !in(i):
+			t.Fatalf("%s: input %d is outside the pool", name, i)
+		}
+		seen[i] = true
+	}
+}
+
+func TestPoolLocal(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		radius int
+		pct    float64
+		size   int
+	}{
+		{"unbounded", 0, 0.5, 512},
+		{"radius", 16, 0.5, 16},
+		{"radius full", 16, 1, 33},
+		{"covers input", 600, 0.5, 512},
+		{"exceeds input", 5000, 1, 1024},
+	} {
+		p := pool{kind: PotentialLocal, numColumns: 64, numInputs: 1024,
+			radius: c.radius, pct: c.pct}
+		p.validate()
+		for _, cell := range []int{0, 1, 31, 63} {
+			center := cell * p.numInputs / p.numColumns
+			idxs := p.sample(cell)
+			if len(idxs) != c.size {
+				t.Fatalf("%s: cell %d has %d inputs, want %d", c.name, cell, len(idxs), c.size)
+			}
+			checkPool(t, c.name, idxs, func(i int) bool {
+				in := i >= 0 && i < p.numInputs
+				if c.radius > 0 {
+					in = in && distance(center, i, p.numInputs) <= c.radius
+				}
+				return in
+			})
+		}
+	}
+}
+
+func TestPoolUniform(t *testing.T) {
+	p := pool{kind: PotentialUniform, numColumns: 64, numInputs: 1024,
+		radius: 4, pct: 0.25}
+	p.validate()
+	for _, cell := range []int{0, 32, 63} {
+		idxs := p.sample(cell)
+		if len(idxs) != 256 {
+			t.Fatalf("cell %d has %d inputs, want 256", cell, len(idxs))
+		}
+		checkPool(t, "uniform", idxs, func(i int) bool { return i >= 0 && i < p.numInputs })
+	}
+}
+
+func TestPoolGaussian(t *testing.T) {
+	p := pool{kind: PotentialGaussian, numColumns: 64, numInputs: 1024,
+		radius: 8, pct: 1}
+	p.validate()
+
+	var total int
+	for cell := 0; cell < p.numColumns; cell++ {
+		center := cell * p.numInputs / p.numColumns
+		idxs := p.sample(cell)
+		total += len(idxs)
+		checkPool(t, "gaussian", idxs, func(i int) bool {
+			return i >= 0 && i < p.numInputs && distance(center, i, p.numInputs) <= 8*p.radius
+		})
+	}
+
+	// sqrt(2 pi) r inputs are expected per cell
+	if mean := float64(total) / float64(p.numColumns); mean < 16 || mean > 24 {
+		t.Fatalf("%.1f inputs per cell, want ~20", mean)
+	}
+}
+
+func TestPoolMask(t *testing.T) {
+	masks := [][]bool{RangeMask(1024, 0, 100), RangeMask(1024, 900, 200)}
+	p := pool{kind: PotentialMask, numColumns: 64, numInputs: 1024,
+		pct: 0.5, masks: masks}
+	p.validate()
+
+	for _, c := range []struct {
+		cell, mask, size int
+	}{
+		{0, 0, 50}, {31, 0, 50}, {32, 1, 62}, {63, 1, 62},
+	} {
+		idxs := p.sample(c.cell)
+		if len(idxs) != c.size {
+			t.Fatalf("cell %d has %d inputs, want %d", c.cell, len(idxs), c.size)
+		}
+		checkPool(t, "mask", idxs, func(i int) bool { return masks[c.mask][i] })
+	}
+}
+
+func TestRangeMask(t *testing.T) {
+	for _, c := range []struct {
+		n, offset, width int
+		first, count     int
+	}{
+		{16, 0, 4, 0, 4},
+		{16, 5, 3, 5, 3},
+		{16, 14, 8, 14, 2},
+		{16, 16, 4, -1, 0},
+	} {
+		mask := RangeMask(c.n, c.offset, c.width)
+		first, count := -1, 0
+		for i, b := range mask {
+			if b {
+				if first < 0 {
+					first = i
+				}
+				count++
+			}
+		}
+		if len(mask) != c.n || first != c.first || count != c.count {
+			t.Fatalf("RangeMask(%d, %d, %d) sets %d from %d, want %d from %d",
+				c.n, c.offset, c.width, count, first, c.count, c.first)
+		}
+	}
+}
+
+func TestPoolValidate(t *testing.T) {
+	for name, p := range map[string]pool{
+		"gaussian radius": {kind: PotentialGaussian, numInputs: 8},
+		"no masks":        {kind: PotentialMask, numInputs: 8},
+		"mask dimensions": {kind: PotentialMask, numInputs: 8, masks: [][]bool{make([]bool, 4)}},
+		"unknown":         {kind: "hexagonal", numInputs: 8},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: did not panic", name)
+				}
+			}()
+			p.validate()
+		}()
+	}
+}
+
+func TestV2LocalPool(t *testing.T) {
+	// a neighborhood wider than the input covers each input once, so
+	// no column grows two synapses to the same input
+	p := NewV2Params()
+	p.NumInputs = 64
+	p.NumColumns = 32
+	p.PotentialRadius = 40
+	p.PotentialPct = 1
+	s := NewV2(p)
+	for c := range s.Cells {
+		if len(s.Cells[c].Synapses) != p.NumInputs {
+			t.Fatalf("column %d has %d synapses, want %d", c, len(s.Cells[c].Synapses), p.NumInputs)
+		}
+		seen := make(map[int]bool)
+		for _, syn := range s.Cells[c].Synapses {
+			if seen[syn.Idx] {
+				t.Fatalf("column %d has two synapses to input %d", c, syn.Idx)
+			}
+			seen[syn.Idx] = true
+		}
+	}
+}
@@ -8,12 +8,14 @@ spatial pooler acts as a feature learning network itself,
 and the pooler output is the result of a competitive
 activation / inhibition process between cells.
 
-There are two spatial pooler implementations working right
-now. Use V2, which fixes some architectural mistakes from V1.
+There are three spatial pooler implementations working right
+now. V2 fixes some architectural mistakes from V1, and V3 is a
+faster rewrite of V2 with the same semantics. Use V3.
 */
 package sp
 
 // SpatialPooler ...
 type SpatialPooler interface {
 	Compute(input []bool, learn bool) []bool
+	Reconstruct(cols []bool) []bool
 }
@@ -0,0 +1,51 @@
+package sp
+
+import "math/rand"
+
+// getInitPerm returns an initial permanence value for a synapse. The
+// returned permanence will be centered on a normal distribution peaking
+// at connected with a standard deviation of 0.1, and will be connected
+// with probability connPct.
+func getInitPerm(connected float32, connPct float64) float32 {
+	sd := 0.1
+	c := float64(connected)
+
+	// Determine if the perm should be connected.
+	var p float64
+	chance := rand.Float64()
+	switch {
+	case chance <= connPct:
+		// Generate a connected permanence.
+		p = rand.NormFloat64()*sd + c
+		for p < c {
+			p = rand.NormFloat64()*sd + c
+		}
+	case chance > connPct:
+		// Generate a non-connected permanence.
+		p = rand.NormFloat64()*sd + c
+		for p >= c {
+			p = rand.NormFloat64()*sd + c
+		}
+	}
+
+	// Clamp 0.0 : 1.0
+	switch {
+	case p > 1.0:
+		p = 1.0
+	case p < 0.0:
+		p = 0.0
+	}
+
+	return float32(p)
+}
+
+// clamp constrains a permanence to [0.0 : 1.0].
+func clamp(perm float32) float32 {
+	switch {
+	case perm > 1.0:
+		return 1.0
+	case perm < 0.0:
+		return 0.0
+	}
+	return perm
+}
@@ -350,3 +350,23 @@ func (sp *V1) updateoverlaps() {
 			sp.cols[i].boostFactor)
 	}
 }
+
+// Reconstruct returns the input bits connected to any of the provided
+// columns. This projects column activity back into input space.
+func (sp *V1) Reconstruct(cols []bool) []bool {
+	if len(cols) != sp.P.NumColumns {
+		panic("Mismatched column dimensions!")
+	}
+
+	input := make([]bool, sp.P.NumInputs)
+	for i := range cols {
+		if cols[i] {
+			for _, syn := range sp.cols[i].psyns {
+				if syn.connected {
+					input[syn.idx] = true
+				}
+			}
+		}
+	}
+	return input
+}
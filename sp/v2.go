@@ -1,39 +1,45 @@
 package sp
 
-import (
-	"math/rand"
-	"sort"
-)
+import "sort"
 
 // V2Params contains parameters for initialization of a V2 SpatialPooler.
 type V2Params struct {
-	NumColumns       int     `json:"numcolumns"`
-	NumInputs        int     `json:"numinputs"`
-	PotentialRadius  int     `json:"potentialradius"`
-	PotentialPct     float64 `json:"potentialpct"`
-	InitConnPct      float64 `json:"initconnpct"`
-	SynPermConnected float32 `json:"synpermconnected"`
-	SynPermMod       float32 `json:"synpermmod"`
-	Sparsity         float64 `json:"sparsity"`
-	DutyCyclePeriod  int     `json:"dutycycleperiod"`
-	MinDutyCycle     float64 `json:"mindutycycle"`
-	MaxBoost         float64 `json:"maxboost"`
+	NumColumns        int       `json:"numcolumns"`
+	NumInputs         int       `json:"numinputs"`
+	Potential         Potential `json:"potential"`
+	PotentialRadius   int       `json:"potentialradius"`
+	PotentialPct      float64   `json:"potentialpct"`
+	PotentialMasks    [][]bool  `json:"potentialmasks,omitempty"`
+	InitConnPct       float64   `json:"initconnpct"`
+	SynPermConnected  float32   `json:"synpermconnected"`
+	SynPermMod        float32   `json:"synpermmod"`
+	Sparsity          float64   `json:"sparsity"`
+	DutyCyclePeriod   int       `json:"dutycycleperiod"`
+	MinDutyCycle      float64   `json:"mindutycycle"`
+	MaxBoost          float64   `json:"maxboost"`
+	Boosting          Boosting  `json:"boosting"`
+	BoostStrength     float64   `json:"booststrength"`
+	StimulusThreshold int       `json:"stimulusthreshold"`
 }
 
 // NewV2Params returns a default set of V2Params.
 func NewV2Params() V2Params {
 	return V2Params{
-		NumColumns:       2048,
-		NumInputs:        1024,
-		PotentialRadius:  0,
-		PotentialPct:     0.02,
-		InitConnPct:      0.3,
-		SynPermConnected: 0.5,
-		SynPermMod:       0.05,
-		Sparsity:         0.02,
-		DutyCyclePeriod:  32,
-		MinDutyCycle:     0.2,
-		MaxBoost:         8.0,
+		NumColumns:        2048,
+		NumInputs:         1024,
+		Potential:         PotentialLocal,
+		PotentialRadius:   0,
+		PotentialPct:      0.02,
+		InitConnPct:       0.3,
+		SynPermConnected:  0.5,
+		SynPermMod:        0.05,
+		Sparsity:          0.02,
+		DutyCyclePeriod:   32,
+		MinDutyCycle:      0.2,
+		MaxBoost:          8.0,
+		Boosting:          BoostLinear,
+		BoostStrength:     100.0,
+		StimulusThreshold: 1,
 	}
 }
 
@@ -42,6 +48,10 @@ type V2 struct {
 	P         V2Params `json:"params"`
 	Cells     []V2Cell `json:"cells"`
 	Iteration int      `json:"iteration"`
+
+	// overlaps from the last step, for inspection
+	overlaps []int
+	boosted  []float64
 }
 
 // NewV2 initializes and returns a new V2 SpatialPooler with the
@@ -54,6 +64,8 @@ func NewV2(p V2Params) *V2 {
 	}
 
 	// Initialize potential synapses
+	s.pool().validate()
+	s.P.Boosting.validate()
 	for i := range s.Cells {
 		s.mapPotential(i)
 		s.Cells[i].oPeriod = make([]int, 0)
@@ -90,6 +102,7 @@ func (s *V2) Compute(input []bool, learn bool) []bool {
 	// Calculate overlaps and inhibit cells
 	overlaps := s.calcOverlaps(input)
 	activeCells := s.inhibitCells(overlaps, learn)
+	s.overlaps = overlaps
 
 	// Perform learning
 	if learn {
@@ -155,17 +168,25 @@ func (s *V2) inhibitCells(overlapScores []int, learn bool) []bool {
 		}
 	}
 
+	s.boosted = make([]float64, len(overlaps))
+	for i := range overlaps {
+		s.boosted[i] = overlaps[i].olap
+	}
+
 	// sort cells by overlap score, descending order
 	sort.Sort(overlaps)
 
 	// calc number of active columns
 	n := int(s.P.Sparsity * float64(s.P.NumColumns))
 
-	// return surviving cells
-	winners := overlaps[:n]
+	// return surviving cells, skipping any below the stimulus
+	// threshold; on weak input fewer than n cells will survive
 	activeCells := make([]bool, s.P.NumColumns)
-	for i := range winners {
-		activeCells[winners[i].idx] = true
+	for i := 0; i < len(overlaps) && n > 0; i++ {
+		if overlapScores[overlaps[i].idx] >= s.P.StimulusThreshold {
+			activeCells[overlaps[i].idx] = true
+			n--
+		}
 	}
 	return activeCells
 }
@@ -280,105 +301,59 @@ func (s *V2) bumpWeakCells() {
 
 // updateBoostFactors
 func (s *V2) updateBoostFactors() {
-	// boost factors are caomputed as an inverse linear relationship
-	// between the active duty cycle and maximum boost setting
-	// only cells with an active duty cycle below s.P.Sparsity are
-	// boosted, all others remain at 1.0
-	var r float64
+	// boost factors are derived from the active duty cycle of each
+	// cell according to s.P.Boosting; see the Boosting constants
 	for i := range s.Cells {
-		switch {
-		case s.Cells[i].activeDutyCycle < s.P.Sparsity:
-			r = 1 - (s.Cells[i].activeDutyCycle / s.P.Sparsity)
-			s.Cells[i].boostFactor = r*s.P.MaxBoost + 1
-		case s.Cells[i].activeDutyCycle > s.P.Sparsity:
-			s.Cells[i].boostFactor = 1.0
-		}
+		s.Cells[i].boostFactor = boostFactor(s.P.Boosting,
+			s.Cells[i].activeDutyCycle, s.P.Sparsity,
+			s.P.MaxBoost, s.P.BoostStrength)
 	}
 }
 
 // mapPotential creates potential synapses on the specified cell. This will
-// grow synapses to a random sample of its receptive field.
+// grow synapses to a sample of its potential pool, chosen according to
+// s.P.Potential.
 func (s *V2) mapPotential(cell int) {
-	// Find centerpoint in input space for this cell
-	ratio := float64(cell) / float64(s.P.NumColumns)
-	center := int(float64(s.P.NumInputs) * ratio)
-
-	// Take random sample of inputs in receptive field of cell
-	nbs := s.getInputNeighbors(center)
-	n := int(float64(len(nbs)) * s.P.PotentialPct)
-	sample := rand.Perm(len(nbs))[:n]
+	sample := s.pool().sample(cell)
 
 	// Grow synapses
 	s.Cells[cell].Synapses = make([]V2Synapse, len(sample))
 	for syn, idx := range sample {
-		s.Cells[cell].Synapses[syn].Idx = nbs[idx]
-		s.Cells[cell].Synapses[syn].Perm = s.getInitPerm()
+		s.Cells[cell].Synapses[syn].Idx = idx
+		s.Cells[cell].Synapses[syn].Perm = getInitPerm(
+			s.P.SynPermConnected, s.P.InitConnPct)
 	}
 }
 
-// getInputNeighbors returns all input indices within PotentialRadius of
-// the provided input index. If PotentialRadius is <= 0, then all input
-// indices are returned.
-func (s *V2) getInputNeighbors(input int) []int {
-	var nbs []int
-	switch {
-	case s.P.PotentialRadius <= 0:
-		nbs = make([]int, s.P.NumInputs)
-		for i := range nbs {
-			nbs[i] = i
-		}
-	default:
-		r := s.P.PotentialRadius
-		nbs = make([]int, 0, r*2+1)
-		for i := input - r; i <= input+r; i++ {
-			// switch to test for overflow / underflow
-			switch {
-			case i >= 0 && i < s.P.NumInputs:
-				// we are within normal range
-				nbs = append(nbs, i)
-			case i < 0:
-				// we are underflowing
-				nbs = append(nbs, i+s.P.NumInputs)
-			case i >= s.P.NumInputs:
-				// we are overflowing
-				nbs = append(nbs, i-s.P.NumInputs)
-			}
-		}
+// pool returns the potential pool params of s.
+func (s *V2) pool() pool {
+	return pool{
+		kind:       s.P.Potential,
+		numColumns: s.P.NumColumns,
+		numInputs:  s.P.NumInputs,
+		radius:     s.P.PotentialRadius,
+		pct:        s.P.PotentialPct,
+		masks:      s.P.PotentialMasks,
 	}
-	return nbs
 }
 
-// getInitPerm returns an initial permanence value for a synapse. The
-// returned permanence will be centered on a normal distribution peaking
-// at SynPermConnected with a standard deviation of 0.05.
-func (s *V2) getInitPerm() float32 {
-	sd := 0.1
-
-	// Determine if the perm should be connected.
-	var p float64
-	chance := rand.Float64()
+// Reconstruct returns the input bits connected to any of the provided
+// columns. This projects column activity back into input space.
+func (s *V2) Reconstruct(cols []bool) []bool {
 	switch {
-	case chance <= s.P.InitConnPct:
-		// Generate a connected permanence.
-		p = rand.NormFloat64()*sd + float64(s.P.SynPermConnected)
-		for p < float64(s.P.SynPermConnected) {
-			p = rand.NormFloat64()*sd + float64(s.P.SynPermConnected)
-		}
-	case chance > s.P.InitConnPct:
-		// Generate a non-connected permanence.
-		p = rand.NormFloat64()*sd + float64(s.P.SynPermConnected)
-		for p >= float64(s.P.SynPermConnected) {
-			p = rand.NormFloat64()*sd + float64(s.P.SynPermConnected)
-		}
+	case len(cols) != s.P.NumColumns:
+		panic("sp: mismatched column dimensions")
 	}
 
-	// Clamp 0.0 : 1.0
-	switch {
-	case p > 1.0:
-		p = 1.0
-	case p < 0.0:
-		p = 0.0
+	input := make([]bool, s.P.NumInputs)
+	for i := range cols {
+		if cols[i] {
+			for _, syn := range s.Cells[i].Synapses {
+				if syn.Perm >= s.P.SynPermConnected {
+					input[syn.Idx] = true
+				}
+			}
+		}
 	}
-
-	return float32(p)
+	return input
 }
@@ -0,0 +1,121 @@
+package sp_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/vec"
+)
+
+func TestV2StimulusThreshold(t *testing.T) {
+	p := sp.NewV2Params()
+	s := sp.NewV2(p)
+	empty := make([]bool, p.NumInputs)
+	for i := 0; i < 4; i++ {
+		if got := len(vec.ToInt(s.Compute(empty, true))); got != 0 {
+			t.Fatalf("step %d: %d active columns on empty input, want 0", i, got)
+		}
+	}
+
+	// only columns with at least StimulusThreshold raw overlap win
+	p.StimulusThreshold = 3
+	s = sp.NewV2(p)
+	for i, input := range inputs(16) {
+		active := s.Compute(input, true)
+		overlaps := s.Overlaps()
+		for _, c := range vec.ToInt(active) {
+			if overlaps[c] < p.StimulusThreshold {
+				t.Fatalf("input %d: column %d won with overlap %d", i, c, overlaps[c])
+			}
+		}
+	}
+
+	p.StimulusThreshold = p.NumInputs + 1
+	s = sp.NewV2(p)
+	for i, input := range inputs(4) {
+		if got := len(vec.ToInt(s.Compute(input, true))); got != 0 {
+			t.Fatalf("input %d: %d active columns above an unreachable threshold", i, got)
+		}
+	}
+}
+
+func TestV2Boosting(t *testing.T) {
+	for _, boost := range []sp.Boosting{sp.BoostLinear, sp.BoostExponential, sp.BoostNone} {
+		p := sp.NewV2Params()
+		p.Boosting = boost
+		s := sp.NewV2(p)
+		for _, input := range inputs(8) {
+			s.Compute(input, true)
+		}
+
+		factors, duty := s.BoostFactors(), s.ActiveDutyCycles()
+		var over, under int
+		for i := range factors {
+			var want float64
+			switch boost {
+			case sp.BoostLinear:
+				want = 1.0
+				if duty[i] < p.Sparsity {
+					want = (1-duty[i]/p.Sparsity)*p.MaxBoost + 1
+				}
+			case sp.BoostExponential:
+				want = math.Exp(-p.BoostStrength * (duty[i] - p.Sparsity))
+			case sp.BoostNone:
+				want = 1.0
+			}
+			if math.Abs(factors[i]-want) > 1e-9 {
+				t.Fatalf("%s: column %d with duty cycle %.3f boosted by %.3f, want %.3f",
+					boost, i, duty[i], factors[i], want)
+			}
+
+			switch {
+			case factors[i] > 1:
+				under++
+			case factors[i] < 1:
+				over++
+			}
+		}
+
+		// exponential boosting damps overactive columns below 1.0,
+		// linear boosting never does
+		switch boost {
+		case sp.BoostExponential:
+			if over == 0 || under == 0 {
+				t.Fatalf("%s: %d columns damped and %d boosted", boost, over, under)
+			}
+		case sp.BoostLinear:
+			if over != 0 || under == 0 {
+				t.Fatalf("%s: %d columns damped and %d boosted", boost, over, under)
+			}
+		case sp.BoostNone:
+			if over != 0 || under != 0 {
+				t.Fatalf("%s: %d columns damped and %d boosted", boost, over, under)
+			}
+		}
+	}
+}
+
+func TestUnknownBoosting(t *testing.T) {
+	for _, fn := range []func(){
+		func() {
+			p := sp.NewV2Params()
+			p.Boosting = "sigmoid"
+			sp.NewV2(p)
+		},
+		func() {
+			p := sp.NewV3Params()
+			p.Boosting = "Linear"
+			sp.NewV3(p)
+		},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("unknown boosting strategy did not panic")
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,329 @@
+package sp
+
+import "sort"
+
+// V3Params contains parameters for initialization of a V3 SpatialPooler.
+type V3Params struct {
+	NumColumns        int       `json:"numcolumns"`
+	NumInputs         int       `json:"numinputs"`
+	Potential         Potential `json:"potential"`
+	PotentialRadius   int       `json:"potentialradius"`
+	PotentialPct      float64   `json:"potentialpct"`
+	PotentialMasks    [][]bool  `json:"potentialmasks,omitempty"`
+	InitConnPct       float64   `json:"initconnpct"`
+	SynPermConnected  float32   `json:"synpermconnected"`
+	SynPermMod        float32   `json:"synpermmod"`
+	Sparsity          float64   `json:"sparsity"`
+	DutyCyclePeriod   int       `json:"dutycycleperiod"`
+	MinDutyCycle      float64   `json:"mindutycycle"`
+	MaxBoost          float64   `json:"maxboost"`
+	Boosting          Boosting  `json:"boosting"`
+	BoostStrength     float64   `json:"booststrength"`
+	StimulusThreshold int       `json:"stimulusthreshold"`
+}
+
+// NewV3Params returns a default set of V3Params.
+func NewV3Params() V3Params {
+	return V3Params{
+		NumColumns:        2048,
+		NumInputs:         1024,
+		Potential:         PotentialLocal,
+		PotentialRadius:   0,
+		PotentialPct:      0.02,
+		InitConnPct:       0.3,
+		SynPermConnected:  0.5,
+		SynPermMod:        0.05,
+		Sparsity:          0.02,
+		DutyCyclePeriod:   32,
+		MinDutyCycle:      0.2,
+		MaxBoost:          8.0,
+		Boosting:          BoostLinear,
+		BoostStrength:     100.0,
+		StimulusThreshold: 1,
+	}
+}
+
+// V3 SpatialPooler. A rewrite of V2 with the same semantics, that
+// does less work per step:
+//
+// Each cell caches the input indices of its connected synapses, and
+// only refreshes the cache when its permanences change, so overlaps
+// are computed without testing every synapse against the threshold.
+//
+// Duty cycles are averages over the last DutyCyclePeriod steps, as in
+// V2, but are kept as running sums over a ring buffer of each cell's
+// history, so they are updated in constant time instead of re-summing
+// the whole history every step.
+//
+// Boost factors are recomputed from scratch each step, so cells whose
+// duty cycle has recovered to exactly Sparsity lose their boost.
+//
+// Like V2, cells with a raw overlap below StimulusThreshold never win
+// inhibition, and the boosting strategy is selected with Boosting.
+type V3 struct {
+	P         V3Params `json:"params"`
+	Cells     []V3Cell `json:"cells"`
+	Iteration int      `json:"iteration"`
+
+	cached   bool
+	overlaps []int
+	boosted  []float64
+	inh      V2InhNet
+	next     int // ring buffer position of the oldest duty cycle entry
+}
+
+// V3Cell ...
+type V3Cell struct {
+	Synapses         []V3Synapse `json:"synapses"`
+	BoostFactor      float64     `json:"boostfactor"`
+	OverlapDutyCycle float64     `json:"overlapdutycycle"`
+	ActiveDutyCycle  float64     `json:"activedutycycle"`
+	connected        []int
+	oPeriod          []int
+	oSum             int
+	aPeriod          []bool
+	aSum             int
+}
+
+// V3Synapse ...
+type V3Synapse struct {
+	Idx  int     `json:"idx"`
+	Perm float32 `json:"perm"`
+}
+
+// NewV3 initializes and returns a new V3 SpatialPooler with the
+// provided parameters.
+func NewV3(p V3Params) *V3 {
+	s := &V3{
+		P:         p,
+		Cells:     make([]V3Cell, p.NumColumns),
+		Iteration: 0,
+	}
+
+	if p.DutyCyclePeriod <= 0 {
+		panic("sp: duty cycle period must be > 0")
+	}
+	s.pool().validate()
+	s.P.Boosting.validate()
+	for i := range s.Cells {
+		s.mapPotential(i)
+		s.Cells[i].BoostFactor = 1.0
+	}
+
+	return s
+}
+
+// Compute ...
+func (s *V3) Compute(input []bool, learn bool) []bool {
+	switch {
+	case len(input) != s.P.NumInputs:
+		panic("sp: mismatched input dimensions")
+	}
+
+	if !s.cached {
+		for i := range s.Cells {
+			s.updateConnected(i)
+		}
+		s.overlaps = make([]int, s.P.NumColumns)
+		s.boosted = make([]float64, s.P.NumColumns)
+		s.inh = make(V2InhNet, s.P.NumColumns)
+		s.cached = true
+	}
+
+	// Calculate overlaps and inhibit cells
+	s.calcOverlaps(input)
+	activeCells := s.inhibitCells(learn)
+
+	// Perform learning
+	if learn {
+		s.adaptSynapses(input, activeCells)
+		s.updateDutyCycles(activeCells)
+		s.bumpWeakCells()
+		s.updateBoostFactors()
+		s.Iteration++
+	}
+
+	return activeCells
+}
+
+// updateConnected refreshes the cached connected synapses of a cell.
+// This should be called every time a synapse on the cell is modified.
+func (s *V3) updateConnected(cell int) {
+	c := &s.Cells[cell]
+	c.connected = c.connected[:0]
+	for j := range c.Synapses {
+		if c.Synapses[j].Perm >= s.P.SynPermConnected {
+			c.connected = append(c.connected, c.Synapses[j].Idx)
+		}
+	}
+}
+
+// calcOverlaps ...
+func (s *V3) calcOverlaps(input []bool) {
+	for i := range s.Cells {
+		var o int
+		for _, idx := range s.Cells[i].connected {
+			if input[idx] {
+				o++
+			}
+		}
+		s.overlaps[i] = o
+	}
+}
+
+// inhibitCells ...
+func (s *V3) inhibitCells(learn bool) []bool {
+	// apply boosting if learning is enabled
+	for i := range s.inh {
+		s.inh[i].idx = i
+		s.inh[i].olap = float64(s.overlaps[i])
+		if learn {
+			s.inh[i].olap *= s.Cells[i].BoostFactor
+		}
+		s.boosted[i] = s.inh[i].olap
+	}
+
+	// sort cells by overlap score, descending order
+	sort.Sort(s.inh)
+
+	// return surviving cells, skipping any below the stimulus
+	// threshold; on weak input fewer than n cells will survive
+	n := int(s.P.Sparsity * float64(s.P.NumColumns))
+	activeCells := make([]bool, s.P.NumColumns)
+	for i := 0; i < len(s.inh) && n > 0; i++ {
+		if s.overlaps[s.inh[i].idx] >= s.P.StimulusThreshold {
+			activeCells[s.inh[i].idx] = true
+			n--
+		}
+	}
+	return activeCells
+}
+
+// adaptSynapses ...
+func (s *V3) adaptSynapses(input []bool, activeCells []bool) {
+	for i := range activeCells {
+		if !activeCells[i] {
+			continue
+		}
+
+		syns := s.Cells[i].Synapses
+		for j := range syns {
+			// bump up contributing, bump down non-contributing
+			switch input[syns[j].Idx] {
+			case true:
+				syns[j].Perm = clamp(syns[j].Perm + s.P.SynPermMod)
+			case false:
+				syns[j].Perm = clamp(syns[j].Perm - s.P.SynPermMod)
+			}
+		}
+		s.updateConnected(i)
+	}
+}
+
+// updateDutyCycles ...
+func (s *V3) updateDutyCycles(activeCells []bool) {
+	// duty cycles are moving averages over the last DutyCyclePeriod
+	// steps; the overlap duty cycle of the number of inputs overlapping
+	// each cell, and the active duty cycle of the frequency each cell
+	// activates
+	full := len(s.Cells) > 0 && len(s.Cells[0].oPeriod) == s.P.DutyCyclePeriod
+	for i := range s.Cells {
+		c := &s.Cells[i]
+		switch full {
+		case true:
+			c.oSum -= c.oPeriod[s.next]
+			if c.aPeriod[s.next] {
+				c.aSum--
+			}
+			c.oPeriod[s.next] = s.overlaps[i]
+			c.aPeriod[s.next] = activeCells[i]
+		case false:
+			c.oPeriod = append(c.oPeriod, s.overlaps[i])
+			c.aPeriod = append(c.aPeriod, activeCells[i])
+		}
+
+		c.oSum += s.overlaps[i]
+		if activeCells[i] {
+			c.aSum++
+		}
+		c.OverlapDutyCycle = float64(c.oSum) / float64(len(c.oPeriod))
+		c.ActiveDutyCycle = float64(c.aSum) / float64(len(c.aPeriod))
+	}
+	if full {
+		s.next = (s.next + 1) % s.P.DutyCyclePeriod
+	}
+}
+
+// bumpWeakCells ...
+func (s *V3) bumpWeakCells() {
+	// increase permanence on all synapses
+	// belonging to weak cells
+	for i := range s.Cells {
+		if s.Cells[i].OverlapDutyCycle < s.P.MinDutyCycle {
+			syns := s.Cells[i].Synapses
+			for j := range syns {
+				syns[j].Perm = clamp(syns[j].Perm + s.P.SynPermMod)
+			}
+			s.updateConnected(i)
+		}
+	}
+}
+
+// updateBoostFactors ...
+func (s *V3) updateBoostFactors() {
+	// boost factors are derived from the active duty cycle of each
+	// cell according to s.P.Boosting; see the Boosting constants
+	for i := range s.Cells {
+		s.Cells[i].BoostFactor = boostFactor(s.P.Boosting,
+			s.Cells[i].ActiveDutyCycle, s.P.Sparsity,
+			s.P.MaxBoost, s.P.BoostStrength)
+	}
+}
+
+// Reconstruct returns the input bits connected to any of the provided
+// columns. This projects column activity back into input space.
+func (s *V3) Reconstruct(cols []bool) []bool {
+	switch {
+	case len(cols) != s.P.NumColumns:
+		panic("sp: mismatched column dimensions")
+	}
+
+	input := make([]bool, s.P.NumInputs)
+	for i := range cols {
+		if cols[i] {
+			for _, syn := range s.Cells[i].Synapses {
+				if syn.Perm >= s.P.SynPermConnected {
+					input[syn.Idx] = true
+				}
+			}
+		}
+	}
+	return input
+}
+
+// mapPotential creates potential synapses on the specified cell. This will
+// grow synapses to a sample of its potential pool, chosen according to
+// s.P.Potential.
+func (s *V3) mapPotential(cell int) {
+	sample := s.pool().sample(cell)
+
+	// Grow synapses
+	s.Cells[cell].Synapses = make([]V3Synapse, len(sample))
+	for syn, idx := range sample {
+		s.Cells[cell].Synapses[syn].Idx = idx
+		s.Cells[cell].Synapses[syn].Perm = getInitPerm(
+			s.P.SynPermConnected, s.P.InitConnPct)
+	}
+}
+
+// pool returns the potential pool params of s.
+func (s *V3) pool() pool {
+	return pool{
+		kind:       s.P.Potential,
+		numColumns: s.P.NumColumns,
+		numInputs:  s.P.NumInputs,
+		radius:     s.P.PotentialRadius,
+		pct:        s.P.PotentialPct,
+		masks:      s.P.PotentialMasks,
+	}
+}
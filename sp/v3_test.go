@@ -0,0 +1,102 @@
+package sp_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/stats"
+	"github.com/nytopop/gohtm/vec"
+)
+
+// inputs returns n random inputs of 1024 bits with 40 active.
+func inputs(n int) [][]bool {
+	rng := rand.New(rand.NewSource(1))
+	out := make([][]bool, n)
+	for i := range out {
+		out[i] = vec.Random(1024, 0.04, rng)
+	}
+	return out
+}
+
+func TestV3Sparsity(t *testing.T) {
+	p := sp.NewV3Params()
+	s := sp.NewV3(p)
+	want := int(p.Sparsity * float64(p.NumColumns))
+
+	for i, input := range inputs(64) {
+		if got := len(vec.ToInt(s.Compute(input, true))); got != want {
+			t.Fatalf("input %d: %d active columns, want %d", i, got, want)
+		}
+	}
+}
+
+func TestV3StimulusThreshold(t *testing.T) {
+	s := sp.NewV3(sp.NewV3Params())
+	empty := make([]bool, s.P.NumInputs)
+
+	for i := 0; i < 4; i++ {
+		if got := len(vec.ToInt(s.Compute(empty, true))); got != 0 {
+			t.Fatalf("step %d: %d active columns on empty input, want 0", i, got)
+		}
+	}
+}
+
+func TestV3Stability(t *testing.T) {
+	s := sp.NewV3(sp.NewV3Params())
+	stability := stats.Stability(s, inputs(32), 8)
+
+	first, last := stability[0], stability[len(stability)-1]
+	if last <= first {
+		t.Fatalf("stability fell from %.3f to %.3f over %d epochs",
+			first, last, len(stability))
+	}
+}
+
+func TestV3MatchesV2(t *testing.T) {
+	for _, boost := range []sp.Boosting{sp.BoostLinear, sp.BoostExponential, sp.BoostNone} {
+		p := sp.NewV2Params()
+		p.Boosting = boost
+		p.DutyCyclePeriod = 8
+
+		// both draw their synapses from the global source in the
+		// same order, so equal seeds give equal initial states
+		rand.Seed(1)
+		v2 := sp.NewV2(p)
+		rand.Seed(1)
+		v3 := sp.NewV3(sp.V3Params(p))
+
+		for epoch := 0; epoch < 3; epoch++ {
+			for i, input := range inputs(16) {
+				learn := epoch < 2
+				a, b := v2.Compute(input, learn), v3.Compute(input, learn)
+				if !vec.Equal(vec.ToInt(a), vec.ToInt(b)) {
+					t.Fatalf("%s: epoch %d input %d: V2 and V3 outputs differ", boost, epoch, i)
+				}
+			}
+			d2, d3 := v2.ActiveDutyCycles(), v3.ActiveDutyCycles()
+			o2, o3 := v2.OverlapDutyCycles(), v3.OverlapDutyCycles()
+			for i := range d2 {
+				if d2[i] != d3[i] || o2[i] != o3[i] {
+					t.Fatalf("%s: epoch %d: cell %d duty cycles differ", boost, epoch, i)
+				}
+			}
+		}
+	}
+}
+
+func benchmarkCompute(b *testing.B, s sp.SpatialPooler) {
+	in := inputs(64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Compute(in[i%len(in)], true)
+	}
+}
+
+func BenchmarkV2Compute(b *testing.B) {
+	benchmarkCompute(b, sp.NewV2(sp.NewV2Params()))
+}
+
+func BenchmarkV3Compute(b *testing.B) {
+	benchmarkCompute(b, sp.NewV3(sp.NewV3Params()))
+}
@@ -0,0 +1,204 @@
+/*
+Package stats provides measures of SDR quality, for verifying the
+output of spatial poolers.
+
+A good pooler uses all of its columns about equally often (high
+entropy, no dead columns), represents different inputs with
+different outputs (low pairwise overlap), is tolerant of noise in
+its input, and settles on stable representations as it learns.
+
+All measures run with learning disabled unless stated otherwise,
+so they can be taken without disturbing the pooler under test.
+*/
+package stats
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/vec"
+)
+
+// Report summarizes the output of a spatial pooler over a dataset.
+type Report struct {
+	Sparsity        float64   `json:"sparsity"`
+	Frequency       []float64 `json:"frequency"`
+	DeadColumns     int       `json:"deadcolumns"`
+	Entropy         float64   `json:"entropy"`
+	PairwiseOverlap float64   `json:"pairwiseoverlap"`
+}
+
+// Analyze computes a Report for the output of s over inputs.
+func Analyze(s sp.SpatialPooler, inputs [][]bool) Report {
+	outputs := Outputs(s, inputs)
+	freq := Frequency(outputs)
+
+	var r Report
+	for _, out := range outputs {
+		r.Sparsity += vec.Sparsity(out)
+	}
+	if len(outputs) > 0 {
+		r.Sparsity /= float64(len(outputs))
+	}
+	for i := range freq {
+		if freq[i] == 0.0 {
+			r.DeadColumns++
+		}
+	}
+	r.Frequency = freq
+	r.Entropy = Entropy(freq)
+	r.PairwiseOverlap = PairwiseOverlap(outputs)
+	return r
+}
+
+// Outputs returns the output of s for each of inputs.
+func Outputs(s sp.SpatialPooler, inputs [][]bool) [][]bool {
+	outputs := make([][]bool, len(inputs))
+	for i := range inputs {
+		outputs[i] = s.Compute(inputs[i], false)
+	}
+	return outputs
+}
+
+// Frequency returns the fraction of outputs each column is active in.
+func Frequency(outputs [][]bool) []float64 {
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	freq := make([]float64, len(outputs[0]))
+	for _, out := range outputs {
+		for i := range out {
+			if out[i] {
+				freq[i]++
+			}
+		}
+	}
+	for i := range freq {
+		freq[i] /= float64(len(outputs))
+	}
+	return freq
+}
+
+// Entropy returns the total binary entropy of the provided column
+// activation frequencies, normalized by the maximum entropy possible
+// at the same mean frequency. A value of 1.0 means every column was
+// used equally often; lower values mean some columns dominate.
+func Entropy(freq []float64) float64 {
+	var total, mean float64
+	for i := range freq {
+		total += binaryEntropy(freq[i])
+		mean += freq[i]
+	}
+	if len(freq) == 0 {
+		return 0.0
+	}
+	mean /= float64(len(freq))
+
+	max := binaryEntropy(mean) * float64(len(freq))
+	if max == 0.0 {
+		return 0.0
+	}
+	return total / max
+}
+
+func binaryEntropy(p float64) float64 {
+	if p <= 0.0 || p >= 1.0 {
+		return 0.0
+	}
+	return -p*math.Log2(p) - (1-p)*math.Log2(1-p)
+}
+
+// PairwiseOverlap returns the mean overlap between all pairs of
+// outputs, as a fraction of the mean number of active bits. Distinct
+// inputs should produce distinct outputs, so lower is better.
+func PairwiseOverlap(outputs [][]bool) float64 {
+	if len(outputs) < 2 {
+		return 0.0
+	}
+
+	sdrs := make([]*vec.SDR, len(outputs))
+	var active float64
+	for i := range outputs {
+		sdrs[i] = vec.NewSDR(len(outputs[i])).SetBool(outputs[i])
+		active += float64(sdrs[i].Count())
+	}
+	active /= float64(len(outputs))
+	if active == 0.0 {
+		return 0.0
+	}
+
+	var total float64
+	var pairs int
+	for i := range sdrs {
+		for j := i + 1; j < len(sdrs); j++ {
+			total += float64(sdrs[i].Overlap(sdrs[j]))
+			pairs++
+		}
+	}
+	return total / float64(pairs) / active
+}
+
+// NoiseRobustness returns, for each of rates, the mean overlap between
+// the output of s for a clean input and for the same input with that
+// fraction of all its bits inverted. The overlap is a fraction of the
+// clean output's active bits, so 1.0 means the output was unaffected
+// by noise. Inputs are sparse, so even small rates add many bits.
+func NoiseRobustness(s sp.SpatialPooler, inputs [][]bool,
+	rates []float64, seed int64) []float64 {
+
+	rng := rand.New(rand.NewSource(seed))
+	clean := Outputs(s, inputs)
+	robust := make([]float64, len(rates))
+
+	for r := range rates {
+		for i := range inputs {
+			noisy := s.Compute(vec.Flip(inputs[i], rates[r], rng), false)
+			robust[r] += overlapRatio(clean[i], noisy)
+		}
+		robust[r] /= float64(len(inputs))
+	}
+	return robust
+}
+
+// Stability trains s on inputs for the provided number of epochs, and
+// returns for each epoch the mean overlap between the outputs for each
+// input before and after that epoch of learning. Values approaching
+// 1.0 mean the pooler has settled on its representations.
+func Stability(s sp.SpatialPooler, inputs [][]bool, epochs int) []float64 {
+	stability := make([]float64, epochs)
+	prev := Outputs(s, inputs)
+
+	for e := 0; e < epochs; e++ {
+		for i := range inputs {
+			s.Compute(inputs[i], true)
+		}
+
+		cur := Outputs(s, inputs)
+		for i := range cur {
+			stability[e] += overlapRatio(prev[i], cur[i])
+		}
+		stability[e] /= float64(len(inputs))
+		prev = cur
+	}
+	return stability
+}
+
+// overlapRatio returns the overlap of a and b as a fraction of the
+// active bits in a.
+func overlapRatio(a, b []bool) float64 {
+	var n, overlap int
+	for i := range a {
+		if a[i] {
+			n++
+			if b[i] {
+				overlap++
+			}
+		}
+	}
+	if n == 0 {
+		return 0.0
+	}
+	return float64(overlap) / float64(n)
+}
@@ -0,0 +1,197 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/vec"
+)
+
+// encodings returns the encodings of n consecutive buckets of a scalar
+// encoder with 40 active bits.
+func encodings(n int) [][]bool {
+	e := enc.NewRDScalar(1024, 40, 0, 1)
+	inputs := make([][]bool, n)
+	for i := range inputs {
+		inputs[i], _ = e.Encode(float64(i))
+	}
+	return inputs
+}
+
+func TestEncoderSubsampling(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	inputs := encodings(64)
+
+	// a subsampled encoding should still be closest to an input within
+	// a few buckets of its own, never to a distant one
+	for _, c := range []struct {
+		rate  float64
+		reach int
+	}{
+		{0.0, 0}, {0.1, 4}, {0.2, 8}, {0.3, 12},
+	} {
+		for i := range inputs {
+			dropped := vec.ToInt(vec.Drop(inputs[i], c.rate, rng))
+			if got, want := len(dropped), 40-int(c.rate*40); got != want {
+				t.Fatalf("rate %v: %d bits after drop, want %d", c.rate, got, want)
+			}
+
+			best, olap := -1, -1
+			for j := range inputs {
+				if o := vec.Overlap(dropped, vec.ToInt(inputs[j])); o > olap {
+					best, olap = j, o
+				}
+			}
+			if d := best - i; d > c.reach || d < -c.reach {
+				t.Fatalf("rate %v: input %d matched %d", c.rate, i, best)
+			}
+		}
+	}
+}
+
+func TestPoolerNoiseRobustness(t *testing.T) {
+	inputs := encodings(64)
+	s := sp.NewV2(sp.NewV2Params())
+	Stability(s, inputs, 4)
+
+	rates := []float64{0.0, 0.005, 0.01, 0.02}
+	min := []float64{1.0, 0.5, 0.4, 0.25}
+	robust := NoiseRobustness(s, inputs, rates, 1)
+	for i := range rates {
+		if robust[i] < min[i] {
+			t.Fatalf("rate %v: output overlap %.3f, want >= %.2f",
+				rates[i], robust[i], min[i])
+		}
+		if i > 0 && robust[i] > robust[i-1] {
+			t.Fatalf("rate %v: output overlap rose with noise", rates[i])
+		}
+	}
+}
+
+// sdrs parses SDRs written as strings of 0 and 1.
+func sdrs(ss ...string) [][]bool {
+	out := make([][]bool, len(ss))
+	for i, s := range ss {
+		out[i] = make([]bool, len(s))
+		for j := range s {
+			out[i][j] = s[j] == '1'
+		}
+	}
+	return out
+}
+
+// memorizer is a pooler that outputs its input, but only for inputs
+// it has learned; unlearned inputs are output shifted by one bit.
+type memorizer map[string]bool
+
+func (m memorizer) Compute(input []bool, learn bool) []bool {
+	key := fmtBits(input)
+	if learn {
+		m[key] = true
+	}
+	if m[key] {
+		return append([]bool{}, input...)
+	}
+	return vec.Shift(input, 1)
+}
+
+func (m memorizer) Reconstruct(cols []bool) []bool {
+	return cols
+}
+
+func fmtBits(a []bool) string {
+	b := make([]byte, len(a))
+	for i := range a {
+		b[i] = '0'
+		if a[i] {
+			b[i] = '1'
+		}
+	}
+	return string(b)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestFrequency(t *testing.T) {
+	got := Frequency(sdrs("1100", "1010"))
+	want := []float64{1.0, 0.5, 0.5, 0.0}
+	for i := range want {
+		if !near(got[i], want[i]) {
+			t.Fatalf("frequency %v, want %v", got, want)
+		}
+	}
+	if Frequency(nil) != nil {
+		t.Fatal("frequency of no outputs is not nil")
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	for _, c := range []struct {
+		freq []float64
+		want float64
+	}{
+		{[]float64{0.5, 0.5, 0.5, 0.5}, 1.0},
+		{[]float64{0.25, 0.25, 0.25, 0.25}, 1.0},
+		{[]float64{1.0, 0.0}, 0.0},
+		{[]float64{0.5, 0.5, 0.0, 0.0}, 2 / (4 * binaryEntropy(0.25))},
+		{[]float64{0.0, 0.0}, 0.0},
+		{nil, 0.0},
+	} {
+		if got := Entropy(c.freq); !near(got, c.want) {
+			t.Fatalf("Entropy(%v) = %v, want %v", c.freq, got, c.want)
+		}
+	}
+}
+
+func TestPairwiseOverlap(t *testing.T) {
+	for _, c := range []struct {
+		outputs [][]bool
+		want    float64
+	}{
+		{sdrs("1100", "1100"), 1.0},
+		{sdrs("1100", "0011"), 0.0},
+		{sdrs("1100", "1010", "0110"), 0.5},
+		{sdrs("1100"), 0.0},
+		{sdrs("0000", "0000"), 0.0},
+	} {
+		if got := PairwiseOverlap(c.outputs); !near(got, c.want) {
+			t.Fatalf("PairwiseOverlap(%v) = %v, want %v", c.outputs, got, c.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	m := memorizer{}
+	inputs := sdrs("10100000", "01010000", "00001010", "00000101")
+	for i := range inputs {
+		m.Compute(inputs[i], true)
+	}
+
+	r := Analyze(m, inputs)
+	switch {
+	case !near(r.Sparsity, 0.25):
+		t.Fatalf("sparsity %v, want 0.25", r.Sparsity)
+	case r.DeadColumns != 0:
+		t.Fatalf("%d dead columns, want 0", r.DeadColumns)
+	case !near(r.Entropy, 1.0):
+		t.Fatalf("entropy %v, want 1.0", r.Entropy)
+	case !near(r.PairwiseOverlap, 0.0):
+		t.Fatalf("pairwise overlap %v, want 0.0", r.PairwiseOverlap)
+	}
+}
+
+func TestStability(t *testing.T) {
+	// the first epoch learns every input, changing all outputs
+	got := Stability(memorizer{}, sdrs("1000", "0010"), 3)
+	want := []float64{0.0, 1.0, 1.0}
+	for i := range want {
+		if !near(got[i], want[i]) {
+			t.Fatalf("stability %v, want %v", got, want)
+		}
+	}
+}
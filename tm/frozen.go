@@ -0,0 +1,213 @@
+package tm
+
+import (
+	"github.com/nytopop/gohtm/cells"
+	"github.com/nytopop/gohtm/vec"
+)
+
+// Frozen is the immutable, inference only form of a trained V1, made
+// with Freeze. It holds only learned connectivity, so it can be shared
+// between goroutines; the sequence state of each stream lives in a
+// Session created with NewSession.
+//
+// Only connected synapses are kept, as flat lists of presynaptic cell
+// indices grouped by segment and cell.
+type Frozen struct {
+	P V1Params `json:"params"`
+
+	cellSegs []int32 // offsets into segSyns, per cell
+	segSyns  []int32 // offsets into syns, per segment
+	syns     []int32 // presynaptic cells
+}
+
+// Freeze returns an immutable inference form of e. Later learning on
+// e does not affect the returned Frozen. Freeze panics if e does not
+// use cells.V1 for its cellular state.
+func (e *V1) Freeze() *Frozen {
+	cv, ok := e.Cons.(*cells.V1)
+	if !ok {
+		panic("tm: cannot freeze unknown cellular state")
+	}
+
+	f := &Frozen{
+		P:        e.P,
+		cellSegs: make([]int32, 1, len(cv.Cells)+1),
+		segSyns:  []int32{0},
+	}
+	for i := range cv.Cells {
+		for _, seg := range cv.Cells[i].Segments {
+			for _, syn := range seg.Synapses {
+				if syn.Perm >= e.P.SynPermConnected {
+					f.syns = append(f.syns, int32(syn.Cell))
+				}
+			}
+			f.segSyns = append(f.segSyns, int32(len(f.syns)))
+		}
+		f.cellSegs = append(f.cellSegs, int32(len(f.segSyns)-1))
+	}
+	return f
+}
+
+// NewSession returns a Session for running a sequence through f.
+func (f *Frozen) NewSession() *Session {
+	n := f.P.NumColumns * f.P.CellsPerCol
+	return &Session{
+		f:               f,
+		activeCells:     make([]bool, n),
+		predictedActive: make([]bool, n),
+		segCounts:       make([]int, n),
+		prediction:      make([]bool, f.P.NumColumns),
+	}
+}
+
+// Session is the sequence state of one stream run through a Frozen.
+// It implements TemporalMemory, ignoring the learn flag of Compute.
+// A Session must not be shared between goroutines, but any number of
+// Sessions can share one Frozen.
+type Session struct {
+	f               *Frozen
+	activeCells     []bool
+	predictedActive []bool
+	segCounts       []int
+	prediction      []bool
+	metrics         Metrics
+}
+
+// Compute iterates the session with the provided vector of active
+// columns. Cells with an active segment in a predicted column become
+// active, and unpredicted columns burst.
+func (s *Session) Compute(active []bool, learn bool) {
+	p := s.f.P
+	switch {
+	case len(active) != p.NumColumns:
+		panic("tm: mismatched input dimensions")
+	}
+
+	s.metrics.compute(s.prediction, active, p.MetricsAlpha)
+
+	// activate cells
+	for i := range s.activeCells {
+		s.activeCells[i] = false
+	}
+	for col := range active {
+		if !active[col] {
+			continue
+		}
+		first := col * p.CellsPerCol
+		for cell := first; cell < first+p.CellsPerCol; cell++ {
+			s.activeCells[cell] = !s.prediction[col] || s.segCounts[cell] > 0
+		}
+	}
+	for i := range s.predictedActive {
+		s.predictedActive[i] = s.activeCells[i] && s.segCounts[i] > 0
+	}
+
+	// compute predictive cells and columns for the next step
+	for col := range s.prediction {
+		s.prediction[col] = false
+	}
+	for cell := range s.segCounts {
+		s.segCounts[cell] = s.f.activeSegs(cell, s.activeCells)
+		if s.segCounts[cell] > 0 {
+			s.prediction[cell/p.CellsPerCol] = true
+		}
+	}
+}
+
+// segActive returns whether seg has at least ActiveThreshold
+// connected synapses to active cells.
+func (f *Frozen) segActive(seg int32, active []bool) bool {
+	var live int
+	for _, pre := range f.syns[f.segSyns[seg]:f.segSyns[seg+1]] {
+		if active[pre] {
+			live++
+		}
+	}
+	return live >= f.P.ActiveThreshold
+}
+
+// activeSegs returns the number of active segments on cell.
+func (f *Frozen) activeSegs(cell int, active []bool) int {
+	var n int
+	for seg := f.cellSegs[cell]; seg < f.cellSegs[cell+1]; seg++ {
+		if f.segActive(seg, active) {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset clears sequence state, so the next input is not treated as a
+// continuation of the current sequence.
+func (s *Session) Reset() {
+	for i := range s.activeCells {
+		s.activeCells[i] = false
+		s.predictedActive[i] = false
+		s.segCounts[i] = 0
+	}
+	for i := range s.prediction {
+		s.prediction[i] = false
+	}
+}
+
+// GetActiveCells returns the currently active cells, in []int
+// format.
+func (s *Session) GetActiveCells() []int {
+	return vec.ToInt(s.activeCells)
+}
+
+// GetAnomalyScore returns the current normalized anomaly score.
+func (s *Session) GetAnomalyScore() float64 {
+	return s.metrics.Anomaly
+}
+
+// GetPrediction returns a copy of the current set of depolarized
+// columns.
+func (s *Session) GetPrediction() []bool {
+	cols := make([]bool, len(s.prediction))
+	copy(cols, s.prediction)
+	return cols
+}
+
+// GetPredictiveCells returns the cells depolarized for the next time
+// step, in []int format.
+func (s *Session) GetPredictiveCells() []int {
+	cells := make([]int, 0)
+	for i := range s.segCounts {
+		if s.segCounts[i] > 0 {
+			cells = append(cells, i)
+		}
+	}
+	return cells
+}
+
+// GetPredictedActiveCells returns the currently active cells that
+// were depolarized in the previous time step, in []int format.
+func (s *Session) GetPredictedActiveCells() []int {
+	return vec.ToInt(s.predictedActive)
+}
+
+// GetActiveSegmentCounts returns a copy of the number of active
+// segments on each cell, which is nonzero for predictive cells.
+func (s *Session) GetActiveSegmentCounts() []int {
+	counts := make([]int, len(s.segCounts))
+	copy(counts, s.segCounts)
+	return counts
+}
+
+// GetStats returns the number of segments and connected synapses
+// kept in the Frozen.
+func (s *Session) GetStats() (int, int) {
+	return len(s.f.segSyns) - 1, len(s.f.syns)
+}
+
+// GetMetrics returns prediction accuracy metrics for the current
+// time step, along with their moving averages.
+func (s *Session) GetMetrics() Metrics {
+	return s.metrics
+}
+
+// ResetMetrics clears all accumulated metrics.
+func (s *Session) ResetMetrics() {
+	s.metrics = Metrics{}
+}
@@ -0,0 +1,55 @@
+package tm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nytopop/gohtm/vec"
+)
+
+func TestFrozenMatches(t *testing.T) {
+	p := NewV1Params()
+	p.NumColumns = 1024
+	p.CellsPerCol = 8
+	e := NewV1(p).(*V1)
+
+	seq := sequence(8, p.NumColumns)
+	for i := 0; i < 16; i++ {
+		for _, cols := range seq {
+			e.Compute(cols, true)
+		}
+		e.Reset()
+	}
+
+	// the sequence, a repeat of it without reset, and unseen input
+	run := append(append(append([][]bool{}, seq...), seq...), sequence(12, p.NumColumns)[8:]...)
+	s := e.Freeze().NewSession()
+	e.ResetMetrics()
+
+	var predicted int
+	for i, cols := range run {
+		e.Compute(cols, false)
+		s.Compute(cols, false)
+
+		switch {
+		case !reflect.DeepEqual(s.GetActiveCells(), e.GetActiveCells()):
+			t.Fatalf("step %d: active cells differ", i)
+		case !vec.Equal(vec.ToInt(s.GetPrediction()), vec.ToInt(e.GetPrediction())):
+			t.Fatalf("step %d: predicted columns differ", i)
+		case !reflect.DeepEqual(s.GetPredictiveCells(), e.GetPredictiveCells()):
+			t.Fatalf("step %d: predictive cells differ", i)
+		case !reflect.DeepEqual(s.GetPredictedActiveCells(), e.GetPredictedActiveCells()):
+			t.Fatalf("step %d: predicted active cells differ", i)
+		case !reflect.DeepEqual(s.GetActiveSegmentCounts(), e.GetActiveSegmentCounts()):
+			t.Fatalf("step %d: active segment counts differ", i)
+		case !reflect.DeepEqual(s.GetPredictedPatterns(), e.GetPredictedPatterns()):
+			t.Fatalf("step %d: predicted patterns differ", i)
+		case s.GetMetrics() != e.GetMetrics():
+			t.Fatalf("step %d: metrics %+v, want %+v", i, s.GetMetrics(), e.GetMetrics())
+		}
+		predicted += len(e.GetPredictedActiveCells())
+	}
+	if predicted == 0 {
+		t.Fatal("nothing was predicted, so nothing was compared")
+	}
+}
@@ -0,0 +1,101 @@
+package tm
+
+import "github.com/nytopop/gohtm/anomaly"
+
+// Metrics contains prediction accuracy measurements for a
+// TemporalMemory, taken by comparing the columns predicted in the
+// previous time step against the columns active in the current one.
+type Metrics struct {
+	Iteration int `json:"iteration"`
+
+	// Counts for the current time step.
+	Active            int `json:"active"`            // active columns
+	Predicted         int `json:"predicted"`         // predicted columns
+	PredictedActive   int `json:"predictedactive"`   // predicted & active
+	PredictedInactive int `json:"predictedinactive"` // predicted & inactive
+	Bursting          int `json:"bursting"`          // active & not predicted
+
+	// Ratios for the current time step.
+	Precision    float64 `json:"precision"`    // predicted active : predicted
+	Recall       float64 `json:"recall"`       // predicted active : active
+	BurstingFrac float64 `json:"burstingfrac"` // bursting : active
+	Anomaly      float64 `json:"anomaly"`      // raw anomaly score
+
+	// Exponential moving averages of the above.
+	AvgPrecision         float64 `json:"avgprecision"`
+	AvgRecall            float64 `json:"avgrecall"`
+	AvgBurstingFrac      float64 `json:"avgburstingfrac"`
+	AvgPredictedInactive float64 `json:"avgpredictedinactive"`
+	AvgAnomaly           float64 `json:"avganomaly"`
+
+	// Totals pruned by the forgetting policy.
+	PrunedSegments int `json:"prunedsegments"`
+	PrunedSynapses int `json:"prunedsynapses"`
+}
+
+// compute counts the columns predicted in the previous time step
+// against those active in the current one, and updates m.
+func (m *Metrics) compute(prediction, active []bool, alpha float64) {
+	m.Active, m.Predicted, m.PredictedActive = 0, 0, 0
+	m.PredictedInactive, m.Bursting = 0, 0
+
+	for i := range active {
+		if active[i] {
+			m.Active++
+		}
+
+		if prediction[i] {
+			m.Predicted++
+			switch active[i] {
+			case true:
+				m.PredictedActive++
+			case false:
+				m.PredictedInactive++
+			}
+		} else {
+			if active[i] {
+				m.Bursting++
+			}
+		}
+	}
+
+	m.Anomaly = anomaly.Raw(prediction, active)
+	m.update(alpha)
+}
+
+// defaultMetricsAlpha is the smoothing factor used when none is set.
+const defaultMetricsAlpha = 0.01
+
+// update recomputes the per step ratios from the current counts, then
+// folds them into the moving averages with smoothing factor alpha. The
+// averages are seeded with the first measurement. An alpha <= 0, as in
+// params decoded without one, uses defaultMetricsAlpha.
+func (m *Metrics) update(alpha float64) {
+	if alpha <= 0 {
+		alpha = defaultMetricsAlpha
+	}
+
+	m.Precision, m.Recall, m.BurstingFrac = 0.0, 0.0, 0.0
+	if m.Predicted > 0 {
+		m.Precision = float64(m.PredictedActive) / float64(m.Predicted)
+	}
+	if m.Active > 0 {
+		m.Recall = float64(m.PredictedActive) / float64(m.Active)
+		m.BurstingFrac = float64(m.Bursting) / float64(m.Active)
+	}
+
+	if m.Iteration == 0 {
+		alpha = 1.0
+	}
+	m.AvgPrecision = ema(m.AvgPrecision, m.Precision, alpha)
+	m.AvgRecall = ema(m.AvgRecall, m.Recall, alpha)
+	m.AvgBurstingFrac = ema(m.AvgBurstingFrac, m.BurstingFrac, alpha)
+	m.AvgPredictedInactive = ema(m.AvgPredictedInactive,
+		float64(m.PredictedInactive), alpha)
+	m.AvgAnomaly = ema(m.AvgAnomaly, m.Anomaly, alpha)
+	m.Iteration++
+}
+
+func ema(avg, x, alpha float64) float64 {
+	return avg + alpha*(x-avg)
+}
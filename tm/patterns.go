@@ -0,0 +1,260 @@
+package tm
+
+import "sort"
+
+// Pattern is a group of predicted cells that were learned together,
+// and so make up one of the alternatives being predicted.
+//
+// When a sequence branches, or the current context is ambiguous, the
+// temporal memory depolarizes the cells of several next inputs at
+// once. Cells that were active together, as one input, are the
+// presynaptic cells of the segments grown on the input that followed.
+// So segments sharing at least MatchThreshold predicted presynaptic
+// cells are grouped, and each predicted cell is put with the group of
+// segments it feeds most.
+//
+// Cells that feed no segment, such as the cells of the last input of
+// a sequence, join a predicted cell in their column, or else the cells
+// whose active segments share the most presynaptic cells with theirs.
+// Groups predicting at least MatchThreshold of the same columns are
+// one input learned in several contexts, and are merged.
+//
+// The grouping is a heuristic. It is most reliable once the sequences
+// have been learned, and with inputs that have little overlap.
+type Pattern struct {
+	Cells    []int   `json:"cells"`    // predicted cells
+	Segments int     `json:"segments"` // active segments on Cells
+	Support  float64 `json:"support"`  // fraction of all active segments
+}
+
+type bySupport []Pattern
+
+func (p bySupport) Len() int           { return len(p) }
+func (p bySupport) Less(i, j int) bool { return p[i].Support > p[j].Support }
+func (p bySupport) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// patterns groups the predicted cells into Patterns, ordered by
+// descending support. counts holds the number of active segments on
+// each cell, and active the cells that activated them.
+func (f *Frozen) patterns(counts []int, active []bool) []Pattern {
+	// predicted cells, and their index in cells
+	var cells []int
+	idx := make(map[int32]int)
+	for cell := range counts {
+		if counts[cell] > 0 {
+			idx[int32(cell)] = len(cells)
+			cells = append(cells, cell)
+		}
+	}
+
+	// segments fed by at least MatchThreshold predicted cells, and
+	// the predicted cells feeding them
+	var feeds [][]int
+	for seg := 0; seg+1 < len(f.segSyns); seg++ {
+		var fed []int
+		for _, pre := range f.syns[f.segSyns[seg]:f.segSyns[seg+1]] {
+			if i, ok := idx[pre]; ok {
+				fed = append(fed, i)
+			}
+		}
+		if len(fed) >= f.P.MatchThreshold {
+			feeds = append(feeds, fed)
+		}
+	}
+
+	// join segments sharing at least MatchThreshold predicted cells
+	segs := newUnion(len(feeds))
+	fedBy := make([][]int, len(cells))
+	for s, fed := range feeds {
+		shared := make(map[int]int)
+		for _, i := range fed {
+			for _, r := range fedBy[i] {
+				shared[r]++
+			}
+			fedBy[i] = append(fedBy[i], s)
+		}
+		for r, n := range shared {
+			if n >= f.P.MatchThreshold {
+				segs.join(s, r)
+			}
+		}
+	}
+
+	// each cell belongs to the group of segments it feeds most
+	group := make([]int, len(cells))
+	for i := range cells {
+		votes := make(map[int]int)
+		group[i] = -1
+		for _, s := range fedBy[i] {
+			r := segs.find(s)
+			votes[r]++
+			if group[i] < 0 || votes[r] > votes[group[i]] {
+				group[i] = r
+			}
+		}
+	}
+
+	// a group of fewer than MatchThreshold cells could not match a
+	// segment on its own, so its cells are left to join another
+	size := make(map[int]int)
+	for i := range cells {
+		size[group[i]]++
+	}
+	for i := range cells {
+		if size[group[i]] < f.P.MatchThreshold {
+			group[i] = -1
+		}
+	}
+
+	// a cell in no group joins another predicted cell in its column,
+	// or else the group sharing the most presynaptic cells with its
+	// active segments, or else the other cells sharing any
+	pres := make([][]int32, len(cells))
+	sources := make(map[int32][]int)
+	for i, cell := range cells {
+		for seg := f.cellSegs[cell]; seg < f.cellSegs[cell+1]; seg++ {
+			if !f.segActive(seg, active) {
+				continue
+			}
+			for _, pre := range f.syns[f.segSyns[seg]:f.segSyns[seg+1]] {
+				if active[pre] {
+					pres[i] = append(pres[i], pre)
+					sources[pre] = append(sources[pre], i)
+				}
+			}
+		}
+	}
+
+	rest := newUnion(len(cells))
+	for i, cell := range cells {
+		if group[i] >= 0 {
+			continue
+		}
+		first := cell - cell%f.P.CellsPerCol
+		for c := first; c < first+f.P.CellsPerCol; c++ {
+			if j, ok := idx[int32(c)]; ok && group[j] >= 0 {
+				group[i] = group[j]
+				break
+			}
+		}
+		if group[i] >= 0 {
+			continue
+		}
+
+		votes := make(map[int]int)
+		for _, pre := range pres[i] {
+			for _, j := range sources[pre] {
+				switch {
+				case group[j] >= 0:
+					votes[group[j]]++
+				case j != i:
+					rest.join(i, j)
+				}
+			}
+		}
+		for g, n := range votes {
+			switch {
+			case group[i] < 0, n > votes[group[i]],
+				n == votes[group[i]] && g < group[i]:
+				group[i] = g
+			}
+		}
+	}
+
+	// number the groups, keyed apart for both unions, and count the
+	// columns each group predicts
+	keys := make(map[[2]int]int)
+	kept := make([]int, len(cells))
+	var cols []map[int]bool
+	for i, cell := range cells {
+		key := [2]int{group[i], -1}
+		if group[i] < 0 {
+			key[1] = rest.find(i)
+		}
+		k, ok := keys[key]
+		if !ok {
+			k = len(cols)
+			keys[key] = k
+			cols = append(cols, make(map[int]bool))
+		}
+		kept[i] = k
+		cols[k][cell/f.P.CellsPerCol] = true
+	}
+
+	// groups predicting at least MatchThreshold of the same columns
+	// are one input, learned in different contexts
+	same := newUnion(len(cols))
+	for k := range cols {
+		for r := 0; r < k; r++ {
+			var n int
+			for col := range cols[k] {
+				if cols[r][col] {
+					n++
+				}
+			}
+			if n >= f.P.MatchThreshold {
+				same.join(k, r)
+			}
+		}
+	}
+
+	roots := make(map[int]int)
+	var patterns []Pattern
+	var total int
+	for i, cell := range cells {
+		root := same.find(kept[i])
+		j, ok := roots[root]
+		if !ok {
+			j = len(patterns)
+			roots[root] = j
+			patterns = append(patterns, Pattern{})
+		}
+		patterns[j].Cells = append(patterns[j].Cells, cell)
+		patterns[j].Segments += counts[cell]
+		total += counts[cell]
+	}
+
+	for i := range patterns {
+		patterns[i].Support = float64(patterns[i].Segments) / float64(total)
+	}
+	sort.Sort(bySupport(patterns))
+	return patterns
+}
+
+// union is a disjoint set forest over n elements.
+type union []int
+
+func newUnion(n int) union {
+	u := make(union, n)
+	for i := range u {
+		u[i] = i
+	}
+	return u
+}
+
+func (u union) find(i int) int {
+	if u[i] != i {
+		u[i] = u.find(u[i])
+	}
+	return u[i]
+}
+
+func (u union) join(i, j int) {
+	u[u.find(i)] = u.find(j)
+}
+
+// GetPredictedPatterns returns the distinct patterns being predicted
+// for the next time step, ordered by descending support. A single
+// Pattern is one confident prediction; several are alternatives.
+//
+// It reads the connectivity of a Freeze of e, and so panics if e does
+// not use cells.V1 for its cellular state.
+func (e *V1) GetPredictedPatterns() []Pattern {
+	return e.Freeze().patterns(e.segCounts, e.ActiveCells)
+}
+
+// GetPredictedPatterns returns the distinct patterns being predicted
+// for the next time step, ordered by descending support.
+func (s *Session) GetPredictedPatterns() []Pattern {
+	return s.f.patterns(s.segCounts, s.activeCells)
+}
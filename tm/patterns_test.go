@@ -0,0 +1,152 @@
+package tm_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/nytopop/gohtm/bench"
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/tm"
+)
+
+// model is a spatial pooler feeding a temporal memory, both with
+// default params. The spatial pooler learns only before the temporal
+// memory, so each symbol has one stable set of columns.
+type model struct {
+	e    *enc.Category
+	sp   *sp.V2
+	tm   tm.TemporalMemory
+	cols map[int][]bool
+}
+
+func newModel() *model {
+	sparams := sp.NewV2Params()
+	sparams.Boosting = sp.BoostNone
+	return &model{
+		e:    enc.NewCategory(enc.NewCategoryParams()),
+		sp:   sp.NewV2(sparams),
+		tm:   tm.NewV1(tm.NewV1Params()),
+		cols: make(map[int][]bool),
+	}
+}
+
+// columns returns the columns of symbol.
+func (m *model) columns(symbol int) []bool {
+	if _, ok := m.cols[symbol]; !ok {
+		in, _ := m.e.Encode(symbol)
+		m.cols[symbol] = m.sp.Compute(in, false)
+	}
+	return m.cols[symbol]
+}
+
+func (m *model) compute(symbol int, learn bool) {
+	m.tm.Compute(m.columns(symbol), learn)
+}
+
+// symbol returns which of symbols has the columns of most of the cells
+// in p, and how many of them it has.
+func (m *model) symbol(p tm.Pattern, symbols []int) (int, int) {
+	cellsPerCol := tm.NewV1Params().CellsPerCol
+	best, most := -1, 0
+	for _, v := range symbols {
+		var n int
+		for _, cell := range p.Cells {
+			if m.columns(v)[cell/cellsPerCol] {
+				n++
+			}
+		}
+		if n > most {
+			best, most = v, n
+		}
+	}
+	return best, most
+}
+
+// train returns a model trained on s.
+func train(s bench.Stream) *model {
+	rand.Seed(1)
+	m := newModel()
+
+	var symbols []int
+	seen := make(map[int]bool)
+	for _, v := range s.Values {
+		if !seen[int(v)] {
+			seen[int(v)] = true
+			symbols = append(symbols, int(v))
+		}
+	}
+	for i := 0; i < 32; i++ {
+		for _, v := range symbols {
+			in, _ := m.e.Encode(v)
+			m.sp.Compute(in, true)
+		}
+	}
+
+	for i := range s.Values {
+		if s.Resets[i] {
+			m.tm.Reset()
+		}
+		m.compute(int(s.Values[i]), true)
+	}
+	m.tm.Reset()
+	return m
+}
+
+func TestPredictedPatternsBranching(t *testing.T) {
+	const prefix, branches, length = 3, 3, 3
+	m := train(bench.Branching(600, prefix, branches, length, 1))
+
+	var firsts []int
+	for b := 0; b < branches; b++ {
+		firsts = append(firsts, prefix+b*length)
+	}
+
+	for i := 0; i < prefix; i++ {
+		m.compute(i, false)
+
+		want := 1
+		if i == prefix-1 {
+			want = branches
+		}
+		pats := m.tm.GetPredictedPatterns()
+		if len(pats) != want {
+			t.Fatalf("prefix %d: %d patterns, want %d", i, len(pats), want)
+		}
+
+		var support float64
+		for _, p := range pats {
+			support += p.Support
+		}
+		if support < 0.999 || support > 1.001 {
+			t.Fatalf("prefix %d: support sums to %.3f", i, support)
+		}
+	}
+
+	// each pattern is the first symbol of a different branch
+	seen := make(map[int]bool)
+	for _, p := range m.tm.GetPredictedPatterns() {
+		v, n := m.symbol(p, firsts)
+		switch {
+		case seen[v]:
+			t.Fatalf("symbol %d is split across patterns", v)
+		case n < len(p.Cells)*9/10:
+			t.Fatalf("pattern of %d cells has %d in symbol %d",
+				len(p.Cells), n, v)
+		}
+		seen[v] = true
+	}
+}
+
+func TestPredictedPatternsLinear(t *testing.T) {
+	s := bench.HighOrder(300, 1, 6, 1)
+	m := train(s)
+
+	// every symbol but the last predicts exactly the next
+	for i := 0; i < 5; i++ {
+		m.compute(int(s.Values[i]), false)
+		if n := len(m.tm.GetPredictedPatterns()); n != 1 {
+			t.Fatalf("symbol %d: %d patterns, want 1", i, n)
+		}
+	}
+}
@@ -11,6 +11,8 @@ type Interface interface {
 	Reset()
 	ActiveCells() []bool
 	WinnerCells() []bool
+	PredictiveCells() []bool
+	PredictedActiveCells() []bool
 	Compute(learn bool, cols, basal, apical []bool) error
 }
 
@@ -22,5 +24,11 @@ type TemporalMemory interface {
 	GetActiveCells() []int
 	GetAnomalyScore() float64
 	GetPrediction() []bool
+	GetPredictiveCells() []int
+	GetPredictedActiveCells() []int
+	GetActiveSegmentCounts() []int
+	GetPredictedPatterns() []Pattern
 	GetStats() (segments, synapses int)
+	GetMetrics() Metrics
+	ResetMetrics()
 }
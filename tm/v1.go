@@ -5,10 +5,6 @@ import (
 	"github.com/nytopop/gohtm/vec"
 )
 
-/* TODO
-- create access methods for deltaInf, deltaAcc, and deltaAnm
-*/
-
 // V1Params contains parameters for initialization of a V1
 // TemporalMemory region.
 type V1Params struct {
@@ -23,6 +19,20 @@ type V1Params struct {
 	MaxNewSyns       int     `json:"maxnewsyns"`
 	ActiveThreshold  int     `json:"activethreshold"`
 	MatchThreshold   int     `json:"matchthreshold"`
+	MetricsAlpha     float64 `json:"metricsalpha"`
+
+	// Forgetting policy, applied while learning. Every CleanupPeriod
+	// iterations, dead synapses, empty segments and segments unused
+	// for SegmentMaxAge iterations are pruned; 0 cleans up every
+	// iteration, and a negative period disables cleanup. Every
+	// DecayPeriod iterations, all permanences are decreased by
+	// PermDecay. Iterations are counted from 1, so the first decay
+	// follows DecayPeriod iterations of learning. Zero disables
+	// SegmentMaxAge and DecayPeriod.
+	CleanupPeriod int     `json:"cleanupperiod"`
+	SegmentMaxAge int     `json:"segmentmaxage"`
+	DecayPeriod   int     `json:"decayperiod"`
+	PermDecay     float32 `json:"permdecay"`
 }
 
 // NewV1Params returns a default V1Params.
@@ -39,6 +49,11 @@ func NewV1Params() V1Params {
 		MaxNewSyns:       16,
 		ActiveThreshold:  12,
 		MatchThreshold:   8,
+		MetricsAlpha:     defaultMetricsAlpha,
+		CleanupPeriod:    1,
+		SegmentMaxAge:    0,
+		DecayPeriod:      0,
+		PermDecay:        0.0,
 	}
 }
 
@@ -54,12 +69,12 @@ type V1 struct {
 	ActiveCells     []bool
 	WinnerCells     []bool
 	prediction      []bool
+	segCounts       []int
+	predictedActive []bool
 	iteration       int
 
 	// Metrics
-	deltaInf     float64 // depolarized : active
-	deltaAcc     float64 // correct : incorrect
-	deltaAnm     float64 // (active-correct) : active
+	metrics      Metrics
 	nSegs, nSyns int
 }
 
@@ -81,6 +96,8 @@ func NewV1(p V1Params) TemporalMemory {
 		ActiveCells:     make([]bool, 0, p.NumColumns*p.CellsPerCol),
 		WinnerCells:     make([]bool, 0, p.NumColumns*p.CellsPerCol),
 		prediction:      make([]bool, p.NumColumns),
+		segCounts:       make([]int, p.NumColumns*p.CellsPerCol),
+		predictedActive: make([]bool, p.NumColumns*p.CellsPerCol),
 		iteration:       0,
 	}
 }
@@ -98,21 +115,27 @@ func (e *V1) Compute(active []bool, learn bool) {
 
 	// We compute metrics by taking prediction from last step
 	// and comparing to currently active columns
-	e.computeMetrics(active)
+	e.metrics.compute(e.prediction, active, e.P.MetricsAlpha)
 
 	// Compute active / depolarized cells
 	e.activateCells(active, learn)
+	for i := range e.predictedActive {
+		e.predictedActive[i] = e.ActiveCells[i] && e.segCounts[i] > 0
+	}
 
 	// Compute active & matching dendrite segments
 	e.Cons.Clear()
 	e.Cons.ComputeActivity(e.ActiveCells, e.P.SynPermConnected,
 		e.P.ActiveThreshold, e.P.MatchThreshold)
 
-	// Cleanup neural net
-	e.Cons.Cleanup()
+	// Forget unused synapses and segments
+	if learn {
+		e.forget()
+	}
 
 	// Compute prediction, stats
 	e.prediction = e.Cons.ComputePredictedCols()
+	e.segCounts = e.Cons.ActiveSegsPerCell()
 	e.nSegs, e.nSyns = e.Cons.ComputeStats()
 
 	if learn {
@@ -122,6 +145,22 @@ func (e *V1) Compute(active []bool, learn bool) {
 
 }
 
+// forget applies the forgetting policy for the current iteration.
+func (e *V1) forget() {
+	n := e.iteration + 1
+	if e.P.DecayPeriod > 0 && n%e.P.DecayPeriod == 0 {
+		e.Cons.Decay(e.P.PermDecay)
+	}
+
+	switch {
+	case e.P.CleanupPeriod < 0:
+	case e.P.CleanupPeriod == 0, n%e.P.CleanupPeriod == 0:
+		segs, syns := e.Cons.Cleanup(e.P.SegmentMaxAge)
+		e.metrics.PrunedSegments += segs
+		e.metrics.PrunedSynapses += syns
+	}
+}
+
 // Calculate the active cells using active columns and dendrite segments.
 // Grow and reinforce synapses.
 func (e *V1) activateCells(active []bool, learn bool) {
@@ -266,43 +305,9 @@ func (e *V1) Reset() {
 	e.PrevWinnerCells = make([]bool, e.P.NumColumns*e.P.CellsPerCol)
 	e.ActiveCells = make([]bool, e.P.NumColumns*e.P.CellsPerCol)
 	e.WinnerCells = make([]bool, e.P.NumColumns*e.P.CellsPerCol)
-}
-
-func (e *V1) computeMetrics(active []bool) {
-	var nActive, nGood, nBad, nNil int
-	for i := range active {
-		if active[i] {
-			nActive++
-		}
-
-		if e.prediction[i] {
-			switch active[i] {
-			case true:
-				nGood++
-			case false:
-				nBad++
-			}
-		} else {
-			if active[i] {
-				nNil++
-			}
-		}
-
-	}
-
-	// out of predicted, how many were right? accuracy 0-1
-	// how many were wrong?
-	//
-
-	e.deltaInf = float64(nGood+nBad) / float64(nActive)
-	e.deltaAcc = float64(nGood) / float64(nBad+nGood)
-	e.deltaAnm = float64(nActive-nGood) / float64(nActive)
-
-	/*
-		fmt.Println("good:", nGood)
-		fmt.Println("bad :", nBad)
-		fmt.Println("nil :", nNil)
-	*/
+	e.segCounts = make([]int, e.P.NumColumns*e.P.CellsPerCol)
+	e.predictedActive = make([]bool, e.P.NumColumns*e.P.CellsPerCol)
+	e.prediction = make([]bool, e.P.NumColumns)
 }
 
 // GetActiveCells returns the currently active cells, in []int
@@ -313,15 +318,56 @@ func (e *V1) GetActiveCells() []int {
 
 // GetAnomalyScore returns the current normalized anomaly score.
 func (e *V1) GetAnomalyScore() float64 {
-	return e.deltaAnm
+	return e.metrics.Anomaly
 }
 
-// GetPrediction returns the current set of depolarized columns.
+// GetPrediction returns a copy of the current set of depolarized
+// columns.
 func (e *V1) GetPrediction() []bool {
-	return e.prediction
+	cols := make([]bool, len(e.prediction))
+	copy(cols, e.prediction)
+	return cols
+}
+
+// GetPredictiveCells returns the cells depolarized for the next time
+// step, in []int format.
+func (e *V1) GetPredictiveCells() []int {
+	cells := make([]int, 0)
+	for i := range e.segCounts {
+		if e.segCounts[i] > 0 {
+			cells = append(cells, i)
+		}
+	}
+	return cells
+}
+
+// GetPredictedActiveCells returns the currently active cells that
+// were depolarized in the previous time step, in []int format.
+func (e *V1) GetPredictedActiveCells() []int {
+	return vec.ToInt(e.predictedActive)
+}
+
+// GetActiveSegmentCounts returns a copy of the number of active
+// segments on each cell, which is nonzero for predictive cells. A cell depolarized by
+// more segments is predicted with more confidence.
+func (e *V1) GetActiveSegmentCounts() []int {
+	counts := make([]int, len(e.segCounts))
+	copy(counts, e.segCounts)
+	return counts
 }
 
 // GetStats returns the current number of segments and synapses.
 func (e *V1) GetStats() (int, int) {
 	return e.nSegs, e.nSyns
 }
+
+// GetMetrics returns prediction accuracy metrics for the current
+// time step, along with their moving averages.
+func (e *V1) GetMetrics() Metrics {
+	return e.metrics
+}
+
+// ResetMetrics clears all accumulated metrics.
+func (e *V1) ResetMetrics() {
+	e.metrics = Metrics{}
+}
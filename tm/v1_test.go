@@ -0,0 +1,154 @@
+package tm
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/nytopop/gohtm/cells"
+	"github.com/nytopop/gohtm/vec"
+)
+
+// sequence returns n random column patterns of 40 active columns.
+func sequence(n, cols int) [][]bool {
+	rng := rand.New(rand.NewSource(1))
+	seq := make([][]bool, n)
+	for i := range seq {
+		seq[i] = vec.Random(cols, 40.0/float64(cols), rng)
+	}
+	return seq
+}
+
+func TestV1Reset(t *testing.T) {
+	p := NewV1Params()
+	p.NumColumns = 1024
+	p.CellsPerCol = 8
+	e := NewV1(p).(*V1)
+
+	seq := sequence(4, p.NumColumns)
+	for i := 0; i < 32; i++ {
+		for _, cols := range seq {
+			e.Compute(cols, true)
+		}
+		e.Reset()
+	}
+	e.Compute(seq[0], false)
+	e.Compute(seq[1], false)
+	switch {
+	case len(vec.ToInt(e.GetPrediction())) == 0:
+		t.Fatal("no prediction after training")
+	case len(e.GetPredictedActiveCells()) == 0:
+		t.Fatal("no predicted active cells after training")
+	}
+
+	e.Reset()
+	switch {
+	case len(vec.ToInt(e.GetPrediction())) != 0:
+		t.Fatal("prediction survived reset")
+	case len(e.GetPredictedActiveCells()) != 0:
+		t.Fatal("predicted active cells survived reset")
+	}
+
+	// the first input of a new sequence is unpredicted
+	e.Compute(seq[2], false)
+	if a := e.GetMetrics().Anomaly; a != 1.0 {
+		t.Fatalf("anomaly %.3f after reset, want 1.0", a)
+	}
+}
+
+func TestV1AccessorsCopy(t *testing.T) {
+	p := NewV1Params()
+	p.NumColumns = 1024
+	p.CellsPerCol = 8
+	e := NewV1(p).(*V1)
+
+	seq := sequence(4, p.NumColumns)
+	for i := 0; i < 32; i++ {
+		for _, cols := range seq {
+			e.Compute(cols, true)
+		}
+		e.Reset()
+	}
+	e.Compute(seq[0], false)
+	e.Compute(seq[1], false)
+
+	pred := vec.ToInt(e.GetPrediction())
+	cells := e.GetPredictiveCells()
+	pact := e.GetPredictedActiveCells()
+	counts := e.GetActiveSegmentCounts()
+	if len(pred) == 0 || len(cells) == 0 || len(pact) == 0 {
+		t.Fatal("no prediction after training")
+	}
+
+	// clobber every returned slice
+	g := e.GetPrediction()
+	for i := range g {
+		g[i] = false
+	}
+	e.GetPredictiveCells()[0] = -1
+	e.GetPredictedActiveCells()[0] = -1
+	c := e.GetActiveSegmentCounts()
+	for i := range c {
+		c[i] = 0
+	}
+
+	switch {
+	case !reflect.DeepEqual(vec.ToInt(e.GetPrediction()), pred):
+		t.Fatal("GetPrediction returned internal state")
+	case !reflect.DeepEqual(e.GetPredictiveCells(), cells):
+		t.Fatal("GetPredictiveCells returned internal state")
+	case !reflect.DeepEqual(e.GetPredictedActiveCells(), pact):
+		t.Fatal("GetPredictedActiveCells returned internal state")
+	case !reflect.DeepEqual(e.GetActiveSegmentCounts(), counts):
+		t.Fatal("GetActiveSegmentCounts returned internal state")
+	}
+}
+
+// forgetCounter counts calls to the forgetting methods of Cells.
+type forgetCounter struct {
+	cells.Cells
+	decays, cleanups int
+}
+
+func (c *forgetCounter) Decay(amount float32) {
+	c.decays++
+	c.Cells.Decay(amount)
+}
+
+func (c *forgetCounter) Cleanup(maxAge int) (int, int) {
+	c.cleanups++
+	return c.Cells.Cleanup(maxAge)
+}
+
+func TestV1ForgettingPolicy(t *testing.T) {
+	// periods, and the expected calls over 12 iterations
+	for _, c := range []struct {
+		cleanup, decay   int
+		cleanups, decays int
+	}{
+		{0, 0, 12, 0},
+		{1, 4, 12, 3},
+		{5, 5, 2, 2},
+		{-1, 12, 0, 1},
+	} {
+		p := NewV1Params()
+		p.NumColumns = 256
+		p.CellsPerCol = 4
+		p.CleanupPeriod = c.cleanup
+		p.DecayPeriod = c.decay
+		p.PermDecay = 0.001
+		e := NewV1(p).(*V1)
+		count := &forgetCounter{Cells: e.Cons}
+		e.Cons = count
+
+		for i, cols := range sequence(12, p.NumColumns) {
+			e.Compute(cols, true)
+			if i == 0 && count.decays > 0 {
+				t.Fatalf("%+v: decayed on the first iteration", c)
+			}
+		}
+		if count.cleanups != c.cleanups || count.decays != c.decays {
+			t.Fatalf("%+v: %d cleanups and %d decays", c, count.cleanups, count.decays)
+		}
+	}
+}
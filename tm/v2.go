@@ -45,6 +45,8 @@ type V2 struct {
 	prevWinnerCells []bool
 	activeCells     []bool
 	winnerCells     []bool
+	predictive      []bool
+	predictedActive []bool
 
 	// Metrics
 }
@@ -73,7 +75,16 @@ func NewV2(p V2Params) Interface {
 	}
 }
 
-func (v *V2) Reset() {}
+// Reset clears the active and winner cells, so sequences are not
+// learned between the current and next time step.
+func (v *V2) Reset() {
+	v.prevActiveCells = make([]bool, v.P.NumBasalCells)
+	v.prevWinnerCells = make([]bool, v.P.NumBasalCells)
+	v.activeCells = make([]bool, v.P.NumBasalCells)
+	v.winnerCells = make([]bool, v.P.NumBasalCells)
+	v.predictive = make([]bool, v.P.NumBasalCells)
+	v.predictedActive = make([]bool, v.P.NumBasalCells)
+}
 
 func (v *V2) ActiveCells() []bool {
 	return make([]bool, v.P.NumBasalCells)
@@ -83,6 +94,23 @@ func (v *V2) WinnerCells() []bool {
 	return make([]bool, v.P.NumBasalCells)
 }
 
+// PredictiveCells returns a copy of the cells depolarized by the basal
+// and apical input to the last call to Compute, which chose the cells
+// that call activated. They are not a prediction for the next call.
+func (v *V2) PredictiveCells() []bool {
+	cells := make([]bool, v.P.NumBasalCells)
+	copy(cells, v.predictive)
+	return cells
+}
+
+// PredictedActiveCells returns a copy of the cells that were both
+// depolarized and activated in the last call to Compute.
+func (v *V2) PredictedActiveCells() []bool {
+	cells := make([]bool, v.P.NumBasalCells)
+	copy(cells, v.predictedActive)
+	return cells
+}
+
 // feedforward + feedback
 func (v *V2) Compute(learn bool, cols, basal, apical []bool) error {
 	switch {
@@ -110,6 +138,9 @@ func (v *V2) Compute(learn bool, cols, basal, apical []bool) error {
 			active[i] = true
 		}
 	}
+	v.predictive = predicted
+	v.predictedActive = make([]bool, len(active))
+	copy(v.predictedActive, active)
 
 	// bursting
 	for i := range cols {
@@ -0,0 +1,349 @@
+/*
+Package tuning searches for good spatial pooler and temporal memory
+parameters for a dataset.
+
+A search space is a list of numeric fields of sp.V2Params and
+optionally tm.V1Params, named by their json tags, each with a range
+of values to try. Candidates are drawn from the space by random or
+grid search, and evaluated in parallel by training a fresh model on
+the dataset.
+
+Candidates are scored by the quality of the spatial pooler's output,
+as measured by package stats, and by the temporal memory's prediction
+error. The best candidate can be exported as a region spec.
+*/
+package tuning
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nytopop/gohtm/cla"
+	"github.com/nytopop/gohtm/enc"
+	"github.com/nytopop/gohtm/region"
+	"github.com/nytopop/gohtm/sp"
+	"github.com/nytopop/gohtm/stats"
+	"github.com/nytopop/gohtm/tm"
+)
+
+// Search selects how candidates are drawn from a Space.
+type Search string
+
+// Search strategies.
+const (
+	// SearchRandom draws Samples candidates uniformly from the space.
+	SearchRandom Search = "random"
+
+	// SearchGrid tries every combination of Steps evenly spaced values
+	// of each Param.
+	SearchGrid Search = "grid"
+)
+
+// Param is one dimension of a search space; the field of a params
+// struct with json tag Name, ranging over [Min : Max]. Integer fields
+// are rounded, and bool fields are true for values >= 0.5.
+type Param struct {
+	Name  string  `json:"name"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Steps int     `json:"steps"` // grid points, at least 2
+	Log   bool    `json:"log"`   // sample on a log scale
+}
+
+// Space is a search space over sp.V2Params and tm.V1Params.
+type Space struct {
+	SP []Param `json:"sp"`
+	TM []Param `json:"tm"`
+}
+
+// Params contains parameters for a search.
+type Params struct {
+	Space   Space       `json:"space"`
+	Search  Search      `json:"search"`
+	Samples int         `json:"samples"` // candidates for random search
+	Workers int         `json:"workers"` // 0 uses every CPU
+	Seed    int64       `json:"seed"`    // seeds candidate selection only
+	N       uint32      `json:"n"`       // encoder width
+	W       int         `json:"w"`       // encoder active bits
+	R       float64     `json:"r"`       // encoder resolution
+	Predict bool        `json:"predict"` // train a temporal memory
+	SP      sp.V2Params `json:"sp"`      // base params
+	TM      tm.V1Params `json:"tm"`      // base params
+
+	// Score weights; a candidate scores
+	// SDRWeight * (entropy - overlap) - ErrorWeight * error.
+	SDRWeight   float64 `json:"sdrweight"`
+	ErrorWeight float64 `json:"errorweight"`
+}
+
+// NewParams returns a default set of Params, with a small random
+// search over the most sensitive spatial pooler params.
+func NewParams() Params {
+	p := Params{
+		Space: Space{
+			SP: []Param{
+				{Name: "potentialpct", Min: 0.01, Max: 0.5, Steps: 3, Log: true},
+				{Name: "synpermmod", Min: 0.01, Max: 0.1, Steps: 3},
+				{Name: "maxboost", Min: 1, Max: 16, Steps: 3},
+			},
+		},
+		Search:      SearchRandom,
+		Samples:     16,
+		Workers:     0,
+		Seed:        1,
+		N:           1024,
+		W:           40,
+		R:           1,
+		Predict:     true,
+		SP:          sp.NewV2Params(),
+		TM:          tm.NewV1Params(),
+		SDRWeight:   1.0,
+		ErrorWeight: 1.0,
+	}
+	p.SP.NumInputs = int(p.N)
+	p.TM.NumColumns = p.SP.NumColumns
+	return p
+}
+
+// Result is the evaluation of one candidate.
+type Result struct {
+	SP              sp.V2Params `json:"sp"`
+	TM              tm.V1Params `json:"tm"`
+	Entropy         float64     `json:"entropy"`
+	PairwiseOverlap float64     `json:"pairwiseoverlap"`
+	DeadColumns     int         `json:"deadcolumns"`
+	Error           float64     `json:"error"`
+	Score           float64     `json:"score"`
+	Err             error       `json:"-"`
+}
+
+// Run searches p.Space for the best parameters for data, and returns
+// the evaluated candidates, best first. Candidates that could not be
+// built or run, such as those naming unknown fields or with invalid
+// combinations of params, are last with Err set.
+//
+// Seed makes the drawn candidates reproducible, but not their scores;
+// models are initialized from the global math/rand source, by several
+// workers at once, so the same candidate may score differently from
+// run to run.
+func Run(data []float64, p Params) []Result {
+	cands := candidates(p)
+
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]Result, len(cands))
+	idx := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				results[i] = evaluate(data, cands[i], p)
+			}
+		}()
+	}
+	for i := range cands {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+
+	sort.Sort(byScore(results))
+	return results
+}
+
+type byScore []Result
+
+func (r byScore) Len() int      { return len(r) }
+func (r byScore) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r byScore) Less(i, j int) bool {
+	switch {
+	case r[i].Err != nil || r[j].Err != nil:
+		return r[j].Err != nil && r[i].Err == nil
+	}
+	return r[i].Score > r[j].Score
+}
+
+// candidate is a point in a search space.
+type candidate struct {
+	sp, tm []float64
+}
+
+// candidates draws the candidates to evaluate from p.Space.
+func candidates(p Params) []candidate {
+	dims := append(append([]Param{}, p.Space.SP...), p.Space.TM...)
+	split := func(v []float64) candidate {
+		return candidate{sp: v[:len(p.Space.SP)], tm: v[len(p.Space.SP):]}
+	}
+
+	var out []candidate
+	switch p.Search {
+	case SearchGrid:
+		cur := make([]float64, len(dims))
+		var walk func(d int)
+		walk = func(d int) {
+			if d == len(dims) {
+				v := make([]float64, len(cur))
+				copy(v, cur)
+				out = append(out, split(v))
+				return
+			}
+			steps := dims[d].Steps
+			if steps < 2 {
+				steps = 2
+			}
+			for i := 0; i < steps; i++ {
+				cur[d] = dims[d].at(float64(i) / float64(steps-1))
+				walk(d + 1)
+			}
+		}
+		walk(0)
+
+	default:
+		rng := rand.New(rand.NewSource(p.Seed))
+		for i := 0; i < p.Samples; i++ {
+			v := make([]float64, len(dims))
+			for d := range dims {
+				v[d] = dims[d].at(rng.Float64())
+			}
+			out = append(out, split(v))
+		}
+	}
+	return out
+}
+
+// at returns the value a fraction f of the way through the range of x.
+func (x Param) at(f float64) float64 {
+	if x.Log && x.Min > 0 && x.Max > 0 {
+		lo, hi := math.Log(x.Min), math.Log(x.Max)
+		return math.Exp(lo + f*(hi-lo))
+	}
+	return x.Min + f*(x.Max-x.Min)
+}
+
+// evaluate builds a model from a candidate, trains it on data and
+// scores it. Invalid params that panic in the model fail the candidate.
+func evaluate(data []float64, c candidate, p Params) (r Result) {
+	r = Result{SP: p.SP, TM: p.TM}
+	defer func() {
+		if e := recover(); e != nil {
+			r.Err = fmt.Errorf("tuning: %v", e)
+		}
+	}()
+
+	for i, x := range p.Space.SP {
+		if err := set(&r.SP, x.Name, c.sp[i]); err != nil {
+			r.Err = err
+			return r
+		}
+	}
+	for i, x := range p.Space.TM {
+		if err := set(&r.TM, x.Name, c.tm[i]); err != nil {
+			r.Err = err
+			return r
+		}
+	}
+	r.SP.NumInputs = int(p.N)
+	r.TM.NumColumns = r.SP.NumColumns
+
+	e := enc.NewRDScalar(p.N, p.W, 0, p.R)
+	s := sp.NewV2(r.SP)
+	var t tm.TemporalMemory
+	if p.Predict {
+		t = tm.NewV1(r.TM)
+	}
+
+	// train, measuring prediction error over the second half of the
+	// data once the model has had a chance to learn
+	inputs := make(map[int][]bool)
+	var errs float64
+	var n int
+	for i := range data {
+		input, b := e.Encode(data[i])
+		inputs[b] = input
+
+		cols := s.Compute(input, true)
+		if t != nil {
+			t.Compute(cols, true)
+			if i >= len(data)/2 {
+				errs += t.GetMetrics().Anomaly
+				n++
+			}
+		}
+	}
+	if n > 0 {
+		r.Error = errs / float64(n)
+	}
+
+	// measure output quality over each distinct input
+	distinct := make([][]bool, 0, len(inputs))
+	for _, input := range inputs {
+		distinct = append(distinct, input)
+	}
+	rep := stats.Analyze(s, distinct)
+	r.Entropy = rep.Entropy
+	r.PairwiseOverlap = rep.PairwiseOverlap
+	r.DeadColumns = rep.DeadColumns
+
+	r.Score = p.SDRWeight*(r.Entropy-r.PairwiseOverlap) -
+		p.ErrorWeight*r.Error
+	return r
+}
+
+// set assigns v to the field of the struct pointed to by ptr with json
+// tag name.
+func set(ptr interface{}, name string, v float64) error {
+	rv := reflect.ValueOf(ptr).Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		tag := strings.Split(rt.Field(i).Tag.Get("json"), ",")[0]
+		if tag != name {
+			continue
+		}
+
+		f := rv.Field(i)
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f.SetInt(int64(math.Floor(v + 0.5)))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f.SetUint(uint64(math.Max(math.Floor(v+0.5), 0)))
+		case reflect.Float32, reflect.Float64:
+			f.SetFloat(v)
+		case reflect.Bool:
+			f.SetBool(v >= 0.5)
+		default:
+			return fmt.Errorf("tuning: field %q of %s is not numeric", name, rt)
+		}
+		return nil
+	}
+	return fmt.Errorf("tuning: %s has no field %q", rt, name)
+}
+
+// Spec returns a region spec built from the params of r, with the
+// encoder and classifier used during the search.
+func (r Result) Spec(p Params) region.Spec {
+	return region.Spec{
+		Encoder: region.Component{
+			Type: "rdscalar",
+			Params: map[string]interface{}{
+				"n":          p.N,
+				"w":          p.W,
+				"r":          p.R,
+				"maxOverlap": 0,
+			},
+		},
+		SP:         region.Component{Type: "v2", Params: r.SP},
+		TM:         region.Component{Type: "v1", Params: r.TM},
+		Classifier: region.Component{Type: "v2", Params: cla.NewV2Params()},
+	}
+}
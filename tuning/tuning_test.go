@@ -0,0 +1,171 @@
+package tuning
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	type fields struct {
+		I   int     `json:"i"`
+		U   uint32  `json:"u"`
+		F32 float32 `json:"f32"`
+		F   float64 `json:"f,omitempty"`
+		B   bool    `json:"b"`
+		S   string  `json:"s"`
+		N   int
+	}
+
+	for _, c := range []struct {
+		name string
+		v    float64
+		want fields
+		err  bool
+	}{
+		{"i", 2.4, fields{I: 2}, false},
+		{"i", 2.5, fields{I: 3}, false},
+		{"i", -1.6, fields{I: -2}, false},
+		{"u", 7.7, fields{U: 8}, false},
+		{"u", -3, fields{U: 0}, false},
+		{"f32", 0.25, fields{F32: 0.25}, false},
+		{"f", 1e-3, fields{F: 1e-3}, false},
+		{"b", 0.5, fields{B: true}, false},
+		{"b", 0.49, fields{}, false},
+		{"s", 1, fields{}, true},
+		{"N", 1, fields{}, true},
+		{"missing", 1, fields{}, true},
+	} {
+		var got fields
+		err := set(&got, c.name, c.v)
+		if (err != nil) != c.err {
+			t.Errorf("%s = %v: error %v, want error %v", c.name, c.v, err, c.err)
+		}
+		if got != c.want {
+			t.Errorf("%s = %v: got %+v, want %+v", c.name, c.v, got, c.want)
+		}
+	}
+}
+
+func TestGrid(t *testing.T) {
+	p := NewParams()
+	p.Search = SearchGrid
+	p.Space = Space{
+		SP: []Param{
+			{Name: "a", Min: 0, Max: 1, Steps: 3},
+			{Name: "b", Min: 1, Max: 100, Steps: 2, Log: true},
+		},
+		TM: []Param{
+			{Name: "c", Min: 4, Max: 4, Steps: 0}, // at least 2 steps
+		},
+	}
+
+	var got [][]float64
+	for _, c := range candidates(p) {
+		got = append(got, append(append([]float64{}, c.sp...), c.tm...))
+	}
+	want := [][]float64{
+		{0, 1, 4}, {0, 1, 4}, {0, 100, 4}, {0, 100, 4},
+		{0.5, 1, 4}, {0.5, 1, 4}, {0.5, 100, 4}, {0.5, 100, 4},
+		{1, 1, 4}, {1, 1, 4}, {1, 100, 4}, {1, 100, 4},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d candidates, want %d", len(got), len(want))
+	}
+	for i := range want {
+		for d := range want[i] {
+			if math.Abs(got[i][d]-want[i][d]) > 1e-9 {
+				t.Fatalf("candidate %d is %v, want %v", i, got[i], want[i])
+			}
+		}
+	}
+
+	// log scale steps are evenly spaced in log space
+	x := Param{Min: 1, Max: 100, Log: true}
+	if v := x.at(0.5); math.Abs(v-10) > 1e-9 {
+		t.Fatalf("log midpoint of [1 : 100] is %v, want 10", v)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	p := NewParams()
+	a, b := candidates(p), candidates(p)
+	if len(a) != p.Samples {
+		t.Fatalf("%d candidates, want %d", len(a), p.Samples)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("candidates differ for the same seed")
+	}
+	for _, c := range a {
+		for d, x := range p.Space.SP {
+			if c.sp[d] < x.Min || c.sp[d] > x.Max {
+				t.Fatalf("%s = %v, outside [%v : %v]", x.Name, c.sp[d], x.Min, x.Max)
+			}
+		}
+	}
+
+	p.Seed++
+	if reflect.DeepEqual(a, candidates(p)) {
+		t.Fatal("candidates are the same for different seeds")
+	}
+}
+
+func TestByScore(t *testing.T) {
+	fail := errors.New("fail")
+	results := byScore{
+		{Score: 1},
+		{Score: 5, Err: fail},
+		{Score: 3},
+		{Score: -2},
+		{Score: 0, Err: fail},
+		{Score: 4},
+	}
+	sort.Sort(results)
+
+	want := []float64{4, 3, 1, -2}
+	for i, s := range want {
+		if results[i].Err != nil || results[i].Score != s {
+			t.Fatalf("result %d: score %v (err %v), want %v", i, results[i].Score, results[i].Err, s)
+		}
+	}
+	for _, r := range results[len(want):] {
+		if r.Err == nil {
+			t.Fatalf("valid result with score %v after failed results", r.Score)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	p := NewParams()
+	p.SP.NumColumns = 256
+	p.Predict = false
+	p.Samples = 2
+	p.Space.TM = []Param{{Name: "nosuchfield", Min: 0, Max: 1}}
+
+	data := make([]float64, 32)
+	for i := range data {
+		data[i] = float64(i % 8)
+	}
+	results := Run(data, p)
+	if len(results) != p.Samples {
+		t.Fatalf("%d results, want %d", len(results), p.Samples)
+	}
+	for _, r := range results {
+		if r.Err == nil {
+			t.Fatal("candidate with an unknown field did not fail")
+		}
+	}
+
+	p.Space.TM = nil
+	for _, r := range Run(data, p) {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		if r.SP.NumColumns != 256 || r.SP.NumInputs != int(p.N) {
+			t.Fatalf("candidate built with %d columns over %d inputs",
+				r.SP.NumColumns, r.SP.NumInputs)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package vec
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// SDR is a binary vector backed by a packed bitset, using one bit per
+// element instead of the one byte per element of a []bool. The sorted
+// indices of set bits are cached, and rebuilt lazily after mutation.
+//
+// Methods that produce an SDR write their result into the receiver and
+// return it, in the style of math/big, so that callers can reuse
+// existing SDRs instead of allocating new ones on every time step.
+type SDR struct {
+	n      int
+	words  []uint64
+	sparse []int
+	dirty  bool
+}
+
+// NewSDR returns an empty SDR with n bits.
+func NewSDR(n int) *SDR {
+	return &SDR{
+		n:     n,
+		words: make([]uint64, (n+63)/64),
+	}
+}
+
+// Len returns the number of bits in s.
+func (s *SDR) Len() int {
+	return s.n
+}
+
+// Get returns the value of bit i.
+func (s *SDR) Get(i int) bool {
+	s.bound(i)
+	return s.words[i/64]&(1<<uint(i%64)) != 0
+}
+
+// Set sets bit i.
+func (s *SDR) Set(i int) {
+	s.bound(i)
+	s.words[i/64] |= 1 << uint(i%64)
+	s.dirty = true
+}
+
+// Unset clears bit i.
+func (s *SDR) Unset(i int) {
+	s.bound(i)
+	s.words[i/64] &^= 1 << uint(i%64)
+	s.dirty = true
+}
+
+// Reset clears all bits in s.
+func (s *SDR) Reset() *SDR {
+	for i := range s.words {
+		s.words[i] = 0
+	}
+	s.dirty = true
+	return s
+}
+
+// Count returns the number of set bits in s.
+func (s *SDR) Count() int {
+	var n int
+	for _, w := range s.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Sparsity returns the fraction of set bits in s.
+func (s *SDR) Sparsity() float64 {
+	return float64(s.Count()) / float64(s.n)
+}
+
+// Int returns the sorted indices of set bits in s. The returned slice
+// is cached, and must not be modified.
+func (s *SDR) Int() []int {
+	if !s.dirty && s.sparse != nil {
+		return s.sparse
+	}
+
+	s.sparse = s.sparse[:0]
+	for i, w := range s.words {
+		for w != 0 {
+			s.sparse = append(s.sparse, i*64+bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+	s.dirty = false
+	return s.sparse
+}
+
+// Bool returns s as a []bool.
+func (s *SDR) Bool() []bool {
+	out := make([]bool, s.n)
+	for _, i := range s.Int() {
+		out[i] = true
+	}
+	return out
+}
+
+// SetBool sets s to the contents of a, which must have length s.Len().
+func (s *SDR) SetBool(a []bool) *SDR {
+	if len(a) != s.n {
+		panic("vec: mismatched sdr dimensions")
+	}
+	s.Reset()
+	for i := range a {
+		if a[i] {
+			s.words[i/64] |= 1 << uint(i%64)
+		}
+	}
+	return s
+}
+
+// SetInt sets s to the provided indices of set bits, which must be
+// in [0 : s.Len()).
+func (s *SDR) SetInt(a []int) *SDR {
+	s.Reset()
+	for _, i := range a {
+		s.bound(i)
+		s.words[i/64] |= 1 << uint(i%64)
+	}
+	return s
+}
+
+// Copy sets s to a copy of a.
+func (s *SDR) Copy(a *SDR) *SDR {
+	s.check(a)
+	copy(s.words, a.words)
+	s.dirty = true
+	return s
+}
+
+// Overlap returns the number of bits set in both s and a.
+func (s *SDR) Overlap(a *SDR) int {
+	s.check(a)
+	var n int
+	for i := range s.words {
+		n += bits.OnesCount64(s.words[i] & a.words[i])
+	}
+	return n
+}
+
+// Equal returns true if s and a have the same bits set.
+func (s *SDR) Equal(a *SDR) bool {
+	if s.n != a.n {
+		return false
+	}
+	for i := range s.words {
+		if s.words[i] != a.words[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Union sets s to the union of a and b.
+func (s *SDR) Union(a, b *SDR) *SDR {
+	s.check(a)
+	s.check(b)
+	for i := range s.words {
+		s.words[i] = a.words[i] | b.words[i]
+	}
+	s.dirty = true
+	return s
+}
+
+// Intersection sets s to the intersection of a and b.
+func (s *SDR) Intersection(a, b *SDR) *SDR {
+	s.check(a)
+	s.check(b)
+	for i := range s.words {
+		s.words[i] = a.words[i] & b.words[i]
+	}
+	s.dirty = true
+	return s
+}
+
+// Subsample sets s to a random sample of n of the set bits in a. If a
+// has n or fewer bits set, s is set to a copy of a.
+func (s *SDR) Subsample(a *SDR, n int, rng *rand.Rand) *SDR {
+	s.check(a)
+	idx := a.Int()
+	if len(idx) <= n {
+		return s.Copy(a)
+	}
+
+	s.Reset()
+	for _, j := range rng.Perm(len(idx))[:n] {
+		s.words[idx[j]/64] |= 1 << uint(idx[j]%64)
+	}
+	return s
+}
+
+// bound panics if i is not a bit index of s.
+func (s *SDR) bound(i int) {
+	if i < 0 || i >= s.n {
+		panic("vec: sdr index out of range")
+	}
+}
+
+// check panics if s and a differ in size.
+func (s *SDR) check(a *SDR) {
+	if s.n != a.n {
+		panic("vec: mismatched sdr dimensions")
+	}
+}
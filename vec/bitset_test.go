@@ -0,0 +1,81 @@
+package vec
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// pair returns a random []bool of n bits and the same bits as an SDR.
+func pair(n int, sparsity float64, rng *rand.Rand) ([]bool, *SDR) {
+	a := Random(n, sparsity, rng)
+	return a, NewSDR(n).SetBool(a)
+}
+
+func TestSDRMatchesBool(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 63, 64, 65, 1000} {
+		a, sa := pair(n, 0.1, rng)
+		b, sb := pair(n, 0.1, rng)
+
+		if !Equal(sa.Int(), ToInt(a)) {
+			t.Fatalf("n=%d: Int %v, want %v", n, sa.Int(), ToInt(a))
+		}
+		if got, want := sa.Overlap(sb), Overlap(ToInt(a), ToInt(b)); got != want {
+			t.Fatalf("n=%d: Overlap %d, want %d", n, got, want)
+		}
+
+		union, inter := make([]bool, n), make([]bool, n)
+		for i := range a {
+			union[i] = a[i] || b[i]
+			inter[i] = a[i] && b[i]
+		}
+		if got := NewSDR(n).Union(sa, sb).Int(); !Equal(got, ToInt(union)) {
+			t.Fatalf("n=%d: Union %v, want %v", n, got, ToInt(union))
+		}
+		if got := NewSDR(n).Intersection(sa, sb).Int(); !Equal(got, ToInt(inter)) {
+			t.Fatalf("n=%d: Intersection %v, want %v", n, got, ToInt(inter))
+		}
+		if got := sa.Bool(); !Equal(ToInt(got), ToInt(a)) {
+			t.Fatalf("n=%d: Bool round trip differs", n)
+		}
+	}
+}
+
+func TestSDRSubsample(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a, sa := pair(1000, 0.1, rng)
+
+	sub := NewSDR(1000).Subsample(sa, 20, rng)
+	if sub.Count() != 20 {
+		t.Fatalf("Subsample count %d, want 20", sub.Count())
+	}
+	for _, i := range sub.Int() {
+		if !a[i] {
+			t.Fatalf("Subsample set bit %d, which is not set in the source", i)
+		}
+	}
+
+	all := NewSDR(1000).Subsample(sa, 5000, rng)
+	if !all.Equal(sa) {
+		t.Fatalf("Subsample of more bits than set should copy the source")
+	}
+}
+
+func TestSDRBounds(t *testing.T) {
+	for _, fn := range []func(s *SDR){
+		func(s *SDR) { s.SetInt([]int{20}) },
+		func(s *SDR) { s.SetInt([]int{-1}) },
+		func(s *SDR) { s.Set(10) },
+		func(s *SDR) { s.Get(10) },
+		func(s *SDR) { s.Unset(-1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("out of range index did not panic")
+				}
+			}()
+			fn(NewSDR(10))
+		}()
+	}
+}
@@ -0,0 +1,104 @@
+package vec
+
+import "math/rand"
+
+// Random returns a random vector of n bits, with round(n * sparsity)
+// bits set. Sparsity is clamped to [0 : 1].
+func Random(n int, sparsity float64, rng *rand.Rand) []bool {
+	out := make([]bool, n)
+	w := count(n, sparsity, 0.5)
+	for _, i := range rng.Perm(n)[:w] {
+		out[i] = true
+	}
+	return out
+}
+
+// Flip returns a copy of a with the given fraction of all bits
+// inverted, chosen at random. This changes the sparsity of a. Rate is
+// clamped to [0 : 1].
+func Flip(a []bool, rate float64, rng *rand.Rand) []bool {
+	out := make([]bool, len(a))
+	copy(out, a)
+
+	n := count(len(a), rate, 0)
+	for _, i := range rng.Perm(len(a))[:n] {
+		out[i] = !out[i]
+	}
+	return out
+}
+
+// Noise returns a copy of a with the given fraction of active bits
+// moved to random inactive positions. This preserves the sparsity of a.
+// Rate is clamped to [0 : 1], and no more bits move than there are
+// inactive positions.
+func Noise(a []bool, rate float64, rng *rand.Rand) []bool {
+	out := make([]bool, len(a))
+	copy(out, a)
+
+	on, off := make([]int, 0), make([]int, 0, len(a))
+	for i := range a {
+		switch a[i] {
+		case true:
+			on = append(on, i)
+		case false:
+			off = append(off, i)
+		}
+	}
+
+	n := count(len(on), rate, 0)
+	if n > len(off) {
+		n = len(off)
+	}
+	for _, i := range rng.Perm(len(on))[:n] {
+		out[on[i]] = false
+	}
+	for _, i := range rng.Perm(len(off))[:n] {
+		out[off[i]] = true
+	}
+	return out
+}
+
+// Drop returns a copy of a with the given fraction of active bits
+// cleared, chosen at random. This subsamples a. Rate is clamped to
+// [0 : 1].
+func Drop(a []bool, rate float64, rng *rand.Rand) []bool {
+	out := make([]bool, len(a))
+	copy(out, a)
+
+	on := ToInt(a)
+	n := count(len(on), rate, 0)
+	for _, i := range rng.Perm(len(on))[:n] {
+		out[on[i]] = false
+	}
+	return out
+}
+
+// Shift returns a copy of a with all bits rotated n positions to the
+// right, wrapping around at the end. Negative n rotates to the left.
+func Shift(a []bool, n int) []bool {
+	out := make([]bool, len(a))
+	if len(a) == 0 {
+		return out
+	}
+
+	n %= len(a)
+	if n < 0 {
+		n += len(a)
+	}
+	for i := range a {
+		out[(i+n)%len(a)] = a[i]
+	}
+	return out
+}
+
+// count returns how many of n bits the fraction rate covers, with rate
+// clamped to [0 : 1]. round is added before truncating.
+func count(n int, rate, round float64) int {
+	switch {
+	case rate < 0:
+		rate = 0
+	case rate > 1:
+		rate = 1
+	}
+	return int(rate*float64(n) + round)
+}
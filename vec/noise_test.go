@@ -0,0 +1,98 @@
+package vec
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, c := range []struct {
+		sparsity float64
+		want     int
+	}{
+		{0.02, 20}, {-1, 0}, {2, 1000},
+	} {
+		if got := len(ToInt(Random(1000, c.sparsity, rng))); got != c.want {
+			t.Fatalf("sparsity %v: %d bits set, want %d", c.sparsity, got, c.want)
+		}
+	}
+}
+
+func TestNoise(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a := Random(1000, 0.04, rng)
+	for _, rate := range []float64{0, 0.25, 0.5, 1, 2} {
+		b := Noise(a, rate, rng)
+		if got := len(ToInt(b)); got != 40 {
+			t.Fatalf("rate %v: %d bits set, want 40", rate, got)
+		}
+		want := 40 - count(40, rate, 0)
+		if got := Overlap(ToInt(a), ToInt(b)); got != want {
+			t.Fatalf("rate %v: overlap %d, want %d", rate, got, want)
+		}
+	}
+}
+
+func TestDrop(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a := Random(1000, 0.04, rng)
+	for _, c := range []struct {
+		rate float64
+		want int
+	}{
+		{0, 40}, {0.25, 30}, {0.5, 20}, {1, 0}, {2, 0},
+	} {
+		b := Drop(a, c.rate, rng)
+		if got := len(ToInt(b)); got != c.want {
+			t.Fatalf("rate %v: %d bits set, want %d", c.rate, got, c.want)
+		}
+		if got := Overlap(ToInt(a), ToInt(b)); got != c.want {
+			t.Fatalf("rate %v: set bits outside of input", c.rate)
+		}
+	}
+}
+
+func TestFlip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a := Random(1000, 0.04, rng)
+	for _, c := range []struct {
+		rate float64
+		want int
+	}{
+		{0, 0}, {0.1, 100}, {1, 1000}, {2, 1000},
+	} {
+		b := Flip(a, c.rate, rng)
+		var diff int
+		for i := range a {
+			if a[i] != b[i] {
+				diff++
+			}
+		}
+		if diff != c.want {
+			t.Fatalf("rate %v: %d bits flipped, want %d", c.rate, diff, c.want)
+		}
+	}
+}
+
+func TestShift(t *testing.T) {
+	a := []bool{true, false, false, true, false}
+	for _, c := range []struct {
+		n    int
+		want []int
+	}{
+		{0, []int{0, 3}},
+		{1, []int{1, 4}},
+		{2, []int{0, 2}},
+		{5, []int{0, 3}},
+		{-1, []int{2, 4}},
+		{-6, []int{2, 4}},
+	} {
+		if got := ToInt(Shift(a, c.n)); !Equal(got, c.want) {
+			t.Fatalf("n=%d: %v, want %v", c.n, got, c.want)
+		}
+	}
+	if got := Shift(nil, 3); len(got) != 0 {
+		t.Fatalf("empty input: %v", got)
+	}
+}